@@ -0,0 +1,179 @@
+package computeruse
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// ActionTypes are the computer action types that can be executed
+var ActionTypes = []string{"click", "double_click", "move", "drag", "scroll", "type", "keypress", "wait", "screenshot", "goto", "resize", "switch_tab"}
+
+// minViewport and maxViewport bound the viewport size of resize actions
+const (
+	minViewport = 320
+	maxViewport = 3840
+)
+
+// UnsupportedActionError is returned for actions of an unknown type, or of a
+// type the computer cannot perform
+type UnsupportedActionError struct {
+	Type        string
+	Environment string
+}
+
+func (e *UnsupportedActionError) Error() string {
+	if e.Environment != "" {
+		return fmt.Sprintf("%s action is not supported in the %s environment", e.Type, e.Environment)
+	}
+	return fmt.Sprintf("unsupported action type %q", e.Type)
+}
+
+// MouseButtons are the buttons accepted by click actions
+var MouseButtons = []string{"left", "right", "wheel", "back", "forward"}
+
+// NewClick returns a click action at (x, y); button defaults to "left"
+func NewClick(x, y int, button string) (Action, error) {
+	if button == "" {
+		button = "left"
+	}
+	a := Action{Type: "click", X: x, Y: y, Button: button}
+	return a, a.Validate()
+}
+
+// NewDoubleClick returns a double click action at (x, y)
+func NewDoubleClick(x, y int) (Action, error) {
+	a := Action{Type: "double_click", X: x, Y: y}
+	return a, a.Validate()
+}
+
+// NewMove returns an action moving the mouse to (x, y)
+func NewMove(x, y int) (Action, error) {
+	a := Action{Type: "move", X: x, Y: y}
+	return a, a.Validate()
+}
+
+// NewDrag returns an action dragging the mouse with the left button along path
+func NewDrag(path ...Point) (Action, error) {
+	a := Action{Type: "drag", Path: path}
+	return a, a.Validate()
+}
+
+// NewGoto returns an action navigating the browser to url
+func NewGoto(url string) (Action, error) {
+	a := Action{Type: "goto", URL: url}
+	return a, a.Validate()
+}
+
+// NewResize returns an action resizing the browser viewport to width x height
+func NewResize(width, height int) (Action, error) {
+	a := Action{Type: "resize", Width: width, Height: height}
+	return a, a.Validate()
+}
+
+// NewSwitchTab returns an action making the browser tab with the given index,
+// counting from 1, the active one
+func NewSwitchTab(tab int) (Action, error) {
+	a := Action{Type: "switch_tab", Tab: tab}
+	return a, a.Validate()
+}
+
+// NewScroll returns an action scrolling by (scrollX, scrollY) with the mouse at (x, y)
+func NewScroll(x, y, scrollX, scrollY int) (Action, error) {
+	a := Action{Type: "scroll", X: x, Y: y, ScrollX: scrollX, ScrollY: scrollY}
+	return a, a.Validate()
+}
+
+// NewType returns an action typing text into the focused element
+func NewType(text string) (Action, error) {
+	a := Action{Type: "type", Text: text}
+	return a, a.Validate()
+}
+
+// NewKeypress returns an action pressing keys in order
+func NewKeypress(keys ...string) (Action, error) {
+	a := Action{Type: "keypress", Keys: keys}
+	return a, a.Validate()
+}
+
+// NewWait returns an action waiting for the page to settle
+func NewWait() Action {
+	return Action{Type: "wait"}
+}
+
+// NewScreenshot returns an action that only takes a screenshot
+func NewScreenshot() Action {
+	return Action{Type: "screenshot"}
+}
+
+// Validate checks that the action type is known and its fields are well formed
+func (a Action) Validate() error {
+	if !slices.Contains(ActionTypes, a.Type) {
+		return &UnsupportedActionError{Type: a.Type}
+	}
+	switch a.Type {
+	case "click", "double_click", "move", "scroll":
+		if a.X < 0 || a.Y < 0 {
+			return fmt.Errorf("%s action has negative coordinates (%d, %d)", a.Type, a.X, a.Y)
+		}
+	case "drag":
+		if len(a.Path) < 2 {
+			return fmt.Errorf("drag action needs a path of at least 2 points, got %d", len(a.Path))
+		}
+		for _, p := range a.Path {
+			if p.X < 0 || p.Y < 0 {
+				return fmt.Errorf("drag action has negative coordinates (%d, %d)", p.X, p.Y)
+			}
+		}
+	case "goto":
+		u, err := url.Parse(a.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("goto action needs an absolute http(s) URL, got %q", a.URL)
+		}
+	case "resize":
+		if a.Width < minViewport || a.Height < minViewport || a.Width > maxViewport || a.Height > maxViewport {
+			return fmt.Errorf("resize action needs a size between %d and %d pixels, got %dx%d", minViewport, maxViewport, a.Width, a.Height)
+		}
+	case "switch_tab":
+		if a.Tab < 1 {
+			return fmt.Errorf("switch_tab action needs a tab index from 1, got %d", a.Tab)
+		}
+	case "type":
+		if a.Text == "" {
+			return fmt.Errorf("type action has no text")
+		}
+	case "keypress":
+		if len(a.Keys) == 0 {
+			return fmt.Errorf("keypress action has no keys")
+		}
+		for _, key := range a.Keys {
+			if key == "" {
+				return fmt.Errorf("keypress action has an empty key")
+			}
+		}
+	}
+	if a.Type == "click" && a.Button != "" && !slices.Contains(MouseButtons, a.Button) {
+		return fmt.Errorf("click action has unknown button %q", a.Button)
+	}
+	return nil
+}
+
+// ValidateFor validates the action and checks that its coordinates lie within a width x height display
+func (a Action) ValidateFor(width, height int) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	switch a.Type {
+	case "click", "double_click", "move", "scroll":
+		if a.X >= width || a.Y >= height {
+			return fmt.Errorf("%s action at (%d, %d) is outside the %dx%d display", a.Type, a.X, a.Y, width, height)
+		}
+	case "drag":
+		for _, p := range a.Path {
+			if p.X >= width || p.Y >= height {
+				return fmt.Errorf("drag path point (%d, %d) is outside the %dx%d display", p.X, p.Y, width, height)
+			}
+		}
+	}
+	return nil
+}
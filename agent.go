@@ -0,0 +1,117 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+)
+
+// Agent holds a browser and a conversation open across instructions, so a
+// follow-up can refer to the previous answer and the page it was found on
+type Agent struct {
+	client   *Client
+	opts     []Option
+	maxTurns int
+	browser  *Browser
+	observer *Observer
+	// responseID is the last response of the conversation, continued by the next Ask
+	responseID string
+}
+
+// NewAgent creates an agent whose instructions may each take up to maxTurns turns
+func NewAgent(maxTurns int, opts ...Option) *Agent {
+	return NewClient().NewAgent(maxTurns, opts...)
+}
+
+// NewAgent creates an agent with the client's options; see the package-level NewAgent
+func (c *Client) NewAgent(maxTurns int, opts ...Option) *Agent {
+	return &Agent{client: c, opts: opts, maxTurns: maxTurns, observer: NewObserver()}
+}
+
+// Observe subscribes to the actions, frames and URL changes of every
+// instruction, e.g. to mirror the agent's browser in a viewer; see Observer.Subscribe
+func (a *Agent) Observe(buffer int) (<-chan Observation, func()) {
+	return a.observer.Subscribe(buffer)
+}
+
+// Start launches the browser and opens url
+func (a *Agent) Start(ctx context.Context, url string) error {
+	if a.browser != nil {
+		return fmt.Errorf("agent already started")
+	}
+	opts := a.client.options(a.opts)
+	cfg := newConfig(opts)
+	browser, err := LaunchBrowser(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	if cfg.keepProfile {
+		browser.KeepProfile()
+		cfg.events.notice("📁 Browser profile kept at " + browser.ProfileDir())
+	}
+	if len(cfg.cookies) > 0 {
+		if err := browser.SetCookies(ctx, cfg.cookies); err != nil {
+			browser.Close()
+			return err
+		}
+	}
+	if err := browser.Open(ctx, url); err != nil {
+		browser.Close()
+		return fmt.Errorf("error opening browser: %w", err)
+	}
+	a.browser = browser
+	return nil
+}
+
+// Ask runs an instruction in the current conversation and browser and returns
+// the answer. Use AskResult for the full result.
+func (a *Agent) Ask(ctx context.Context, instruction string) (string, error) {
+	result, err := a.AskResult(ctx, instruction)
+	if result == nil {
+		return "", err
+	}
+	return result.Output, err
+}
+
+// AskResult runs an instruction as a follow-up to the previous completed ones.
+// Setup actions and warm starts only apply to the first instruction.
+func (a *Agent) AskResult(ctx context.Context, instruction string, opts ...Option) (*Result, error) {
+	if a.browser == nil {
+		return nil, fmt.Errorf("agent not started")
+	}
+	opts = append(append(a.client.options(a.opts), WithObserver(a.observer)), opts...)
+	if a.responseID != "" {
+		opts = append(opts, withFollowUp(a.responseID, instruction))
+	}
+	result, err := Run(ctx, a.browser, instruction, a.maxTurns, opts...)
+	// An unfinished run may end on a computer call the API still expects an
+	// output for, so only completed instructions extend the conversation
+	if result != nil && result.Status == StatusCompleted {
+		a.responseID = result.ResponseID
+	}
+	return result, err
+}
+
+// Close closes the browser, ends the conversation and closes the observation channels
+func (a *Agent) Close() {
+	a.observer.Close()
+	if a.browser != nil {
+		a.browser.Close()
+		a.browser = nil
+	}
+	a.responseID = ""
+}
+
+// withFollowUp continues the conversation after responseID with instruction,
+// leaving the page as the previous instruction left it
+func withFollowUp(responseID, instruction string) Option {
+	return func(c *config) {
+		c.resume = &Checkpoint{
+			Model:              c.model,
+			Instruction:        instruction,
+			PreviousResponseID: responseID,
+			Pending:            []Input{{Role: "user", Content: instruction}},
+		}
+		c.warmStart = nil
+		c.setupActions = nil
+	}
+}
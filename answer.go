@@ -0,0 +1,56 @@
+package computeruse
+
+import "fmt"
+
+// AnswerError is returned when the final answer still fails a validator after all repair attempts
+type AnswerError struct {
+	Validator string
+	Err       error
+}
+
+func (e *AnswerError) Error() string {
+	return fmt.Sprintf("final answer failed %s validation: %v", e.Validator, e.Err)
+}
+
+func (e *AnswerError) Unwrap() error {
+	return e.Err
+}
+
+// answerValidator checks the final answer and asks the model to fix it when the check fails
+type answerValidator struct {
+	name    string
+	check   func(answer string) error
+	repair  func(err error) string
+	retries int
+	// strict validators fail the run once retries are exhausted, others accept the answer
+	strict bool
+}
+
+// validateAnswer runs the validators in order. It returns a repair message to
+// send to the model, or an error when a strict validator has no retries left.
+func validateAnswer(validators []*answerValidator, answer string) (string, error) {
+	for _, v := range validators {
+		err := v.check(answer)
+		if err == nil {
+			continue
+		}
+		if v.retries > 0 {
+			v.retries--
+			return v.repair(err), nil
+		}
+		if v.strict {
+			return "", &AnswerError{Validator: v.name, Err: err}
+		}
+	}
+	return "", nil
+}
+
+// cloneValidators copies validators so every attempt starts with the full retry budget
+func cloneValidators(validators []*answerValidator) []*answerValidator {
+	clones := make([]*answerValidator, len(validators))
+	for i, v := range validators {
+		clone := *v
+		clones[i] = &clone
+	}
+	return clones
+}
@@ -0,0 +1,159 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+)
+
+// Classes of actions that require approval
+const (
+	ApproveSubmit   = "submit"
+	ApprovePassword = "password"
+	ApproveEnter    = "enter"
+	ApproveAll      = "all"
+)
+
+// ApprovalPolicy selects the actions routed through the ApprovalFunc
+type ApprovalPolicy struct {
+	// Submit covers clicks on buttons that submit a form
+	Submit bool
+	// Password covers typing into password fields
+	Password bool
+	// EnterDomains covers pressing Enter on these domains and their subdomains
+	EnterDomains []string
+	// Everything covers every action except screenshots and waits
+	Everything bool
+}
+
+// ApprovalRequest describes an action waiting for approval
+type ApprovalRequest struct {
+	// Class is the reason approval is needed, e.g. ApproveSubmit
+	Class  string
+	Action Action
+	URL    string
+	// Element is the clicked or focused element, when known
+	Element *ElementInfo
+}
+
+// ApprovalFunc decides whether an action may be executed. A denial is not
+// fatal: the reason is sent to the model so it can choose another way.
+type ApprovalFunc func(ctx context.Context, req ApprovalRequest) (approved bool, reason string)
+
+// ApprovalDeniedError is reported to the model when an action was not approved
+type ApprovalDeniedError struct {
+	Class  string
+	Reason string
+}
+
+func (e *ApprovalDeniedError) Error() string {
+	msg := "the user did not approve this " + e.Class + " action"
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg + ". Do not retry it; find another way or explain in your answer why the task cannot be completed"
+}
+
+// approvalClass returns the class that requires approval for an action, or ""
+func (p *ApprovalPolicy) approvalClass(ctx context.Context, c Computer, action *Action, element *ElementInfo, currentURL string) (string, *ElementInfo) {
+	if p.Everything && action.Type != "screenshot" && action.Type != "wait" {
+		return ApproveAll, element
+	}
+	switch action.Type {
+	case "click", "double_click":
+		if p.Submit && element != nil && element.Submit {
+			return ApproveSubmit, element
+		}
+	case "type":
+		if b, ok := c.(*Browser); ok && p.Password {
+			if focused, err := b.FocusedElement(ctx); err == nil && focused != nil && focused.Type == "password" {
+				return ApprovePassword, focused
+			}
+		}
+	case "keypress":
+		if len(p.EnterDomains) > 0 && pressesEnter(action.Keys) {
+			u, err := url.Parse(currentURL)
+			if err != nil {
+				return "", element
+			}
+			for _, domain := range p.EnterDomains {
+				if domainMatches(u.Hostname(), domain) {
+					return ApproveEnter, element
+				}
+			}
+		}
+	}
+	return "", element
+}
+
+// pressesEnter reports whether a key combination includes Enter
+func pressesEnter(keys []string) bool {
+	for _, k := range splitChords(keys) {
+		switch strings.ToLower(k) {
+		case "enter", "return":
+			return true
+		}
+	}
+	return false
+}
+
+// approve routes an action through the approval function when the policy
+// requires it and returns an *ApprovalDeniedError when it is denied
+func (c *config) approve(ctx context.Context, computer Computer, action *Action, element *ElementInfo) error {
+	if c.approvalFunc == nil {
+		return nil
+	}
+	var currentURL string
+	if u, ok := computer.(urlReporter); ok {
+		currentURL = u.GetCurrentUrl()
+	}
+	class, element := c.approvalPolicy.approvalClass(ctx, computer, action, element, currentURL)
+	if class == "" {
+		return nil
+	}
+	req := ApprovalRequest{Class: class, Action: *action, URL: currentURL, Element: element}
+	approved, reason := c.approvalFunc(ctx, req)
+	c.events.notice(fmt.Sprintf("🙋 Approval for %s %s: %t", class, action.Type, approved))
+	if approved {
+		return nil
+	}
+	return &ApprovalDeniedError{Class: class, Reason: reason}
+}
+
+// TerminalApproval asks an operator on out to approve each action and reads
+// the answer from in. Anything but "y" or "yes" denies the action, and the
+// rest of a denial line is passed to the model as the reason. Prompts on the
+// same in, such as TerminalSafetyCheckHandler's, take turns reading it.
+func TerminalApproval(in io.Reader, out io.Writer) ApprovalFunc {
+	lines := terminalLines(in)
+	return func(ctx context.Context, req ApprovalRequest) (bool, string) {
+		action, _ := json.Marshal(req.Action)
+		fmt.Fprintf(out, "\n🙋 Approval needed (%s): %s\n", req.Class, action)
+		if req.URL != "" {
+			fmt.Fprintf(out, "   URL    : %s\n", req.URL)
+		}
+		if req.Element != nil {
+			fmt.Fprintf(out, "   Element: <%s> %s\n", req.Element.Tag, req.Element.Name)
+		}
+		fmt.Fprint(out, "Approve? [y/N, or a reason to deny]: ")
+
+		a, err := lines.readLine(ctx)
+		if ctx.Err() != nil {
+			fmt.Fprintln(out)
+			return false, "no answer was given in time"
+		}
+		if err != nil {
+			return false, ""
+		}
+		switch strings.ToLower(a) {
+		case "y", "yes":
+			return true, ""
+		case "", "n", "no":
+			return false, ""
+		}
+		return false, a
+	}
+}
@@ -0,0 +1,176 @@
+package computeruse
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Frame is a manifest entry linking a screenshot to the turn and action that produced it
+type Frame struct {
+	Turn   int       `json:"turn"`
+	Action string    `json:"action"`
+	File   string    `json:"file"`
+	URL    string    `json:"url,omitempty"`
+	Time   time.Time `json:"time"`
+	// Reasoning is the model's reasoning summary for the turn, when enabled
+	Reasoning string `json:"reasoning,omitempty"`
+	// Element is the DOM element hit by a click in a browser
+	Element *ElementInfo `json:"element,omitempty"`
+}
+
+// Manifest describes the artifacts saved for a session
+type Manifest struct {
+	SessionID string            `json:"session_id"`
+	Tags      map[string]string `json:"tags,omitempty"`
+	Frames    []Frame           `json:"frames"`
+}
+
+// artifactRecorder names screenshots <dir>/<session>/<turn>-<action>.png and keeps manifest.json up to date
+type artifactRecorder struct {
+	mu        sync.Mutex
+	dir       string
+	turn      int
+	reasoning string
+	manifest  Manifest
+}
+
+// newSessionID returns a sortable, unique session identifier
+func newSessionID() string {
+	b := make([]byte, 3)
+	rand.Read(b)
+	return time.Now().Format("20060102-150405") + "-" + hex.EncodeToString(b)
+}
+
+func newArtifactRecorder(root, sessionID string, tags map[string]string) *artifactRecorder {
+	if sessionID == "" {
+		sessionID = newSessionID()
+	}
+	return &artifactRecorder{
+		dir:      filepath.Join(root, sessionID),
+		manifest: Manifest{SessionID: sessionID, Tags: tags},
+	}
+}
+
+// beginTurn advances the turn counter, which keeps counting across retries
+func (r *artifactRecorder) beginTurn() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.turn++
+	r.reasoning = ""
+}
+
+// setReasoning sets the reasoning summary attached to the frames of the current turn
+func (r *artifactRecorder) setReasoning(text string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reasoning = text
+}
+
+// stem returns the path without extension for the artifacts of an action in the current turn
+func (r *artifactRecorder) stem(action string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stem := filepath.Join(r.dir, fmt.Sprintf("%03d-%s", r.turn, action))
+	// Several actions of the same type in one turn get a numeric suffix
+	for n := 2; r.hasFile(stem + ".png"); n++ {
+		stem = filepath.Join(r.dir, fmt.Sprintf("%03d-%s-%d", r.turn, action, n))
+	}
+	return stem
+}
+
+func (r *artifactRecorder) hasFile(file string) bool {
+	for _, f := range r.manifest.Frames {
+		if f.File == file {
+			return true
+		}
+	}
+	return false
+}
+
+// addFrame records a saved screenshot and rewrites manifest.json
+func (r *artifactRecorder) addFrame(action, file, url string, element *ElementInfo) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manifest.Frames = append(r.manifest.Frames, Frame{
+		Turn:      r.turn,
+		Action:    action,
+		File:      file,
+		URL:       url,
+		Time:      time.Now(),
+		Reasoning: r.reasoning,
+		Element:   element,
+	})
+	data, err := json.MarshalIndent(r.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding manifest: %w", err)
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("error creating artifacts directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, "manifest.json"), data, 0644); err != nil {
+		return fmt.Errorf("error saving manifest: %w", err)
+	}
+	return r.writeReport()
+}
+
+// reportTemplate renders the manifest as a page with one row per screenshot
+var reportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"base": filepath.Base,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Session {{.SessionID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.frame { display: flex; gap: 1.5em; margin-bottom: 2em; }
+.frame img { width: 512px; border: 1px solid #ccc; }
+.reasoning { white-space: pre-wrap; color: #444; }
+</style>
+</head>
+<body>
+<h1>Session {{.SessionID}}</h1>
+{{range .Frames}}
+<div class="frame">
+<img src="{{base .File}}" alt="turn {{.Turn}} {{.Action}}">
+<div>
+<h3>Turn {{.Turn}}: {{.Action}}</h3>
+{{if .URL}}<p>{{.URL}}</p>{{end}}
+{{with .Element}}<p>Clicked &lt;{{.Tag}}&gt; {{.Name}} at ({{.X}}, {{.Y}}) {{.Width}}x{{.Height}}</p>{{end}}
+{{if .Reasoning}}<p class="reasoning">{{.Reasoning}}</p>{{end}}
+</div>
+</div>
+{{end}}
+</body>
+</html>
+`))
+
+// writeReport renders report.html from the manifest; the caller holds r.mu
+func (r *artifactRecorder) writeReport() error {
+	f, err := os.Create(filepath.Join(r.dir, "report.html"))
+	if err != nil {
+		return fmt.Errorf("error creating report: %w", err)
+	}
+	defer f.Close()
+	if err := reportTemplate.Execute(f, r.manifest); err != nil {
+		return fmt.Errorf("error writing report: %w", err)
+	}
+	return nil
+}
+
+// lastFile returns the most recently saved screenshot, or "" before the first one
+func (r *artifactRecorder) lastFile() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.manifest.Frames) == 0 {
+		return ""
+	}
+	return r.manifest.Frames[len(r.manifest.Frames)-1].File
+}
@@ -1,145 +1,629 @@
 package computeruse
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-rod/rod"
 	"github.com/go-rod/rod/lib/input"
+	"github.com/go-rod/rod/lib/launcher"
+	"github.com/go-rod/rod/lib/launcher/flags"
 	"github.com/go-rod/rod/lib/proto"
 )
 
 // Browser represents a browser instance for automation
 type Browser struct {
-	browser *rod.Browser
-	page    *rod.Page
-	width   int
-	height  int
+	browser     *rod.Browser
+	page        *rod.Page
+	width       int
+	height      int
+	human       bool
+	downloads   *downloadTracker
+	launcher    *launcher.Launcher
+	keepProfile bool
+	platform    string
+	remote      bool
+	emulation   emulation
+
+	// tabs are the open tabs of the session, page being the active one. They
+	// change only under mu, since tab events read them.
+	tabs      []*rod.Page
+	tabOpened bool
+	// stopWatch ends the tab event listener started by watchTabs
+	stopWatch context.CancelFunc
+	// interceptChooser is set by EnableFileChooser for the tabs opened later
+	interceptChooser bool
+
+	// mu guards the fields updated by browser events: chooser, the file
+	// chooser waiting for SetInputFiles, and the tabs opened and closed since
+	// the last action
+	mu         sync.Mutex
+	chooser    *proto.PageFileChooserOpened
+	openedTabs []proto.TargetTargetID
+	closedTabs []proto.TargetTargetID
 }
 
-// NewBrowser creates a new browser instance with the specified dimensions
+// NewBrowser creates a new browser instance with the specified dimensions.
+// The browser runs headless with a temporary profile that is removed by Close.
 func NewBrowser(width, height int) *Browser {
-	browser := rod.New().MustConnect()
-	return &Browser{browser, nil, width, height}
+	b, err := launchBrowser(width, height, true)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// LaunchBrowser starts or attaches to a browser as configured by options such
+// as WithRemoteBrowser, WithHeadless, WithUserDataDir, WithProxy,
+// WithLaunchFlags and WithDisplaySize. The identity set with WithUserAgent,
+// WithLocale, WithTimezone and WithLocalStorage applies to the pages it opens.
+func LaunchBrowser(ctx context.Context, opts ...Option) (*Browser, error) {
+	cfg := newConfig(opts)
+	if err := errors.Join(cfg.errs...); err != nil {
+		return nil, err
+	}
+	if cfg.remoteURL != "" {
+		b, err := connectBrowser(ctx, cfg.remoteURL, cfg.displayWidth, cfg.displayHeight)
+		if err != nil {
+			return nil, err
+		}
+		b.emulation = cfg.emulation
+		return b, nil
+	}
+
+	l := launcher.New().Context(ctx).Headless(cfg.headless)
+	if cfg.userDataDir != "" {
+		l = l.UserDataDir(cfg.userDataDir)
+	}
+	if cfg.proxy != "" {
+		l = l.Proxy(cfg.proxy)
+	}
+	if cfg.noSandbox {
+		l = l.NoSandbox(true)
+	}
+	for _, f := range cfg.launchFlags {
+		name, value, _ := strings.Cut(strings.TrimLeft(f, "-"), "=")
+		if value == "" {
+			l = l.Set(flags.Flag(name))
+		} else {
+			l = l.Set(flags.Flag(name), value)
+		}
+	}
+	b, err := startBrowser(l, cfg.displayWidth, cfg.displayHeight)
+	if err != nil {
+		return nil, err
+	}
+	// A user-provided profile is persistent and must survive Close
+	b.keepProfile = cfg.userDataDir != ""
+	b.emulation = cfg.emulation
+	return b, nil
 }
 
-// Close closes the browser instance
+// launchBrowser starts a new local browser in headless or headful mode with a temporary profile
+func launchBrowser(width, height int, headless bool) (*Browser, error) {
+	return startBrowser(launcher.New().Headless(headless), width, height)
+}
+
+// startBrowser launches the browser configured by l and connects to it
+func startBrowser(l *launcher.Launcher, width, height int) (*Browser, error) {
+	u, err := l.Launch()
+	if err != nil {
+		return nil, fmt.Errorf("error launching browser: %w", err)
+	}
+	browser := rod.New().ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		l.Kill()
+		l.Cleanup()
+		return nil, fmt.Errorf("error connecting to browser: %w", err)
+	}
+	return &Browser{browser: browser, width: width, height: height, launcher: l}, nil
+}
+
+// connectBrowser attaches to a running browser through its DevTools endpoint,
+// either a WebSocket URL or the host:port of a remote debugging port
+func connectBrowser(ctx context.Context, remoteURL string, width, height int) (*Browser, error) {
+	u := remoteURL
+	if !strings.HasPrefix(u, "ws://") && !strings.HasPrefix(u, "wss://") {
+		resolved, err := launcher.ResolveURL(u)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving DevTools endpoint %s: %w", remoteURL, err)
+		}
+		u = resolved
+	}
+	browser := rod.New().Context(ctx).ControlURL(u)
+	if err := browser.Connect(); err != nil {
+		return nil, fmt.Errorf("error connecting to browser at %s: %w", remoteURL, err)
+	}
+	// Detach from ctx so later calls are bound by their own contexts
+	return &Browser{browser: browser.Context(context.Background()), width: width, height: height, remote: true}, nil
+}
+
+// Close closes the browser instance and, unless KeepProfile was called,
+// removes its temporary profile with the cache and cookies. A browser attached
+// with WithRemoteBrowser keeps running; only the tabs of the session are closed.
 func (b *Browser) Close() {
-	b.browser.MustClose()
+	if b.stopWatch != nil {
+		b.stopWatch()
+	}
+	if b.remote {
+		b.activePage()
+		for _, tab := range b.tabs {
+			tab.Close()
+		}
+		return
+	}
+	// Closing fails only when the browser is already gone
+	b.browser.Close()
+	if b.launcher != nil && !b.keepProfile {
+		b.launcher.Cleanup()
+	}
 }
 
-// Open opens a URL in the browser
-func (b *Browser) Open(url string) error {
-	page, err := b.browser.Page(proto.TargetCreateTarget{URL: url})
+// KeepProfile leaves the temporary profile on disk when the browser is closed,
+// e.g. to inspect its cache or cookies afterwards
+func (b *Browser) KeepProfile() {
+	b.keepProfile = true
+}
+
+// ProfileDir returns the user data directory of a browser started by NewBrowser
+func (b *Browser) ProfileDir() string {
+	if b.launcher == nil {
+		return ""
+	}
+	return b.launcher.Get(flags.UserDataDir)
+}
+
+// Environment returns the computer tool environment of the browser
+func (b *Browser) Environment() string {
+	return "browser"
+}
+
+// Dimensions returns the viewport size of the browser
+func (b *Browser) Dimensions() (int, int) {
+	return b.width, b.height
+}
+
+// Resize changes the viewport to width x height. Later screenshots and the
+// display size declared to the model follow the new size.
+func (b *Browser) Resize(ctx context.Context, width, height int) error {
+	err := b.do(ctx, func(page *rod.Page) error {
+		return page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+			Width:             width,
+			Height:            height,
+			DeviceScaleFactor: 1,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("error resizing viewport: %w", err)
+	}
+	b.width, b.height = width, height
+	return nil
+}
+
+// Open opens a URL in a new page of the browser and waits for it to settle.
+// The viewport and emulation are set before the URL is loaded.
+func (b *Browser) Open(ctx context.Context, url string) error {
+	page, err := b.browser.Context(ctx).Page(proto.TargetCreateTarget{URL: "about:blank"})
 	if err != nil {
 		return fmt.Errorf("error opening page: %w", err)
 	}
-	page.MustSetViewport(b.width, b.height, 1, false)
-	page.MustWaitStable()
-	b.page = page
+	err = page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             b.width,
+		Height:            b.height,
+		DeviceScaleFactor: 1,
+	})
+	if err != nil {
+		page.Close()
+		return fmt.Errorf("error setting viewport: %w", err)
+	}
+	if err := b.emulation.apply(page); err != nil {
+		page.Close()
+		return err
+	}
+	if err := page.Navigate(url); err != nil {
+		page.Close()
+		return fmt.Errorf("error navigating to %s: %w", url, err)
+	}
+	if err := settle(page, time.Second); err != nil {
+		page.Close()
+		return fmt.Errorf("error waiting for %s to load: %w", url, err)
+	}
+	// Detach from ctx so later calls are bound by their own contexts
+	b.page = page.Context(context.Background())
+	if b.tabs == nil {
+		b.watchTabs()
+	}
+	b.mu.Lock()
+	b.tabs = append(b.tabs, b.page)
+	b.mu.Unlock()
+	return nil
+}
+
+// settle waits for the page to stay unchanged for d after an input event or
+// navigation, giving up after 3*d: the event already took effect, and a page
+// that keeps animating or polling is still worth a screenshot. Only the end of
+// the page's own context is reported.
+func settle(page *rod.Page, d time.Duration) error {
+	limited := page.Timeout(3 * d)
+	defer limited.CancelTimeout()
+	if err := limited.WaitStable(d); err != nil && page.GetContext().Err() != nil {
+		return page.GetContext().Err()
+	}
 	return nil
 }
 
+// Navigate loads url in the current page
+func (b *Browser) Navigate(ctx context.Context, url string) error {
+	return b.do(ctx, func(page *rod.Page) error {
+		if err := page.Navigate(url); err != nil {
+			return fmt.Errorf("error navigating to %s: %w", url, err)
+		}
+		return settle(page, time.Second)
+	})
+}
+
+// do runs fn against the page bound to ctx and gives up as soon as ctx is done,
+// so a single hung CDP call cannot stall the session
+func (b *Browser) do(ctx context.Context, fn func(page *rod.Page) error) error {
+	page := b.activePage()
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(page.Context(ctx))
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // Screenshot takes a screenshot of the current page
-func (b *Browser) Screenshot() ([]byte, error) {
-	screenshot, err := b.page.Screenshot(false, nil)
+func (b *Browser) Screenshot(ctx context.Context) ([]byte, error) {
+	var screenshot []byte
+	err := b.do(ctx, func(page *rod.Page) error {
+		var err error
+		screenshot, err = page.Screenshot(false, nil)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("error taking screenshot: %w", err)
 	}
 	return screenshot, nil
 }
 
+// Snapshot captures the page markup and resources as MHTML
+func (b *Browser) Snapshot(ctx context.Context) ([]byte, error) {
+	var snapshot string
+	err := b.do(ctx, func(page *rod.Page) error {
+		res, err := proto.PageCaptureSnapshot{Format: proto.PageCaptureSnapshotFormatMhtml}.Call(page)
+		if err != nil {
+			return err
+		}
+		snapshot = res.Data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error capturing snapshot: %w", err)
+	}
+	return []byte(snapshot), nil
+}
+
+// ViewportState describes the scroll position of the viewport within the page
+type ViewportState struct {
+	ScrollX    int `json:"scroll_x"`
+	ScrollY    int `json:"scroll_y"`
+	Width      int `json:"width"`
+	Height     int `json:"height"`
+	PageWidth  int `json:"page_width"`
+	PageHeight int `json:"page_height"`
+}
+
+// Viewport returns the current scroll offset and the total page size
+func (b *Browser) Viewport(ctx context.Context) (*ViewportState, error) {
+	var state ViewportState
+	err := b.do(ctx, func(page *rod.Page) error {
+		obj, err := page.Eval(`() => {
+			const el = document.scrollingElement || document.documentElement;
+			return {
+				scroll_x: Math.round(window.scrollX), scroll_y: Math.round(window.scrollY),
+				width: window.innerWidth, height: window.innerHeight,
+				page_width: el.scrollWidth, page_height: el.scrollHeight,
+			};
+		}`)
+		if err != nil {
+			return err
+		}
+		return obj.Value.Unmarshal(&state)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading viewport: %w", err)
+	}
+	return &state, nil
+}
+
+// PageMetadata describes the state of the current page
+type PageMetadata struct {
+	Title      string `json:"title"`
+	HTTPStatus int    `json:"http_status"`
+	LoadState  string `json:"load_state"`
+}
+
+// Metadata returns the title, the HTTP status of the last navigation and the document load state
+func (b *Browser) Metadata(ctx context.Context) (*PageMetadata, error) {
+	var meta PageMetadata
+	err := b.do(ctx, func(page *rod.Page) error {
+		obj, err := page.Eval(`() => {
+			const nav = performance.getEntriesByType("navigation")[0];
+			return {
+				title: document.title,
+				http_status: nav && nav.responseStatus ? nav.responseStatus : 0,
+				load_state: document.readyState,
+			};
+		}`)
+		if err != nil {
+			return err
+		}
+		return obj.Value.Unmarshal(&meta)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading page metadata: %w", err)
+	}
+	return &meta, nil
+}
+
 // GetCurrentUrl returns the current URL of the page
 func (b *Browser) GetCurrentUrl() string {
-	return b.page.MustInfo().URL
-}
-
-// Keypress simulates pressing keys on the keyboard
-func (b *Browser) Keypress(keys []string) {
-	keyb := b.page.Keyboard
-	for _, key := range keys {
-		switch strings.ToLower(key) {
-		case "enter":
-			keyb.Press(input.Enter)
-		case "return":
-			keyb.Press(input.Enter)
-		case "delete":
-			keyb.Press(input.Delete)
-		case "tab":
-			keyb.Press(input.Tab)
-		case "escape":
-			keyb.Press(input.Escape)
-		case "left":
-			keyb.Press(input.ArrowLeft)
-		case "right":
-			keyb.Press(input.ArrowRight)
-		case "up":
-			keyb.Press(input.ArrowUp)
-		case "down":
-			keyb.Press(input.ArrowDown)
-		case "page_up":
-			keyb.Press(input.PageUp)
-		case "page_down":
-			keyb.Press(input.PageDown)
-		default:
-			fmt.Printf("key: %v is not implemented", key)
-		}
-	}
-	b.page.MustWaitStable()
+	// The URL is read outside of any action, so bound it to avoid hanging the loop
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	info, err := b.activePage().Context(ctx).Info()
+	if err != nil {
+		return ""
+	}
+	return info.URL
+}
+
+// Keypress simulates pressing a key combination, e.g. ["ctrl", "f"] or
+// ["ctrl+a"]: modifiers are held down while the other keys are pressed in
+// order, then released in reverse order. Unknown keys fail the action before
+// any key is pressed.
+func (b *Browser) Keypress(ctx context.Context, keys []string) error {
+	return b.do(ctx, func(page *rod.Page) error {
+		names := NormalizeKeys(keys, b.isMac(page))
+		resolved := make([]input.Key, len(names))
+		for i, name := range names {
+			key, ok := lookupKey(name)
+			if !ok {
+				return fmt.Errorf("unknown key %q", name)
+			}
+			resolved[i] = key
+		}
+
+		keyb := page.Keyboard
+		var held []input.Key
+		defer func() {
+			// Never leave a modifier stuck down, even when a key failed
+			for i := len(held) - 1; i >= 0; i-- {
+				keyb.Release(held[i])
+			}
+		}()
+		for i, key := range resolved {
+			if isModifier(names[i]) {
+				if err := keyb.Press(key); err != nil {
+					return fmt.Errorf("error pressing %s: %w", names[i], err)
+				}
+				held = append(held, key)
+				continue
+			}
+			if err := keyb.Type(key); err != nil {
+				return fmt.Errorf("error pressing %s: %w", names[i], err)
+			}
+		}
+		for len(held) > 0 {
+			if err := keyb.Release(held[len(held)-1]); err != nil {
+				return fmt.Errorf("error releasing key: %w", err)
+			}
+			held = held[:len(held)-1]
+		}
+		return settle(page, time.Second)
+	})
+}
+
+// isMac reports whether the browser runs on macOS, where shortcuts use CMD instead of CTRL
+func (b *Browser) isMac(page *rod.Page) bool {
+	if b.platform == "" {
+		obj, err := page.Eval(`() => navigator.platform`)
+		if err != nil {
+			return false
+		}
+		b.platform = obj.Value.Str()
+	}
+	return strings.HasPrefix(b.platform, "Mac")
 }
 
 // Type types text into the active element
-func (b *Browser) Type(text string) {
-	page := b.page
-	page.InsertText(text)
+func (b *Browser) Type(ctx context.Context, text string) error {
+	return b.do(ctx, func(page *rod.Page) error {
+		return b.typeText(page, text)
+	})
 }
 
 // Move moves the mouse to the specified coordinates
-func (b *Browser) Move(x, y int) {
-	mouse := b.page.Mouse
-	mouse.MustMoveTo(float64(x), float64(y))
+func (b *Browser) Move(ctx context.Context, x, y int) error {
+	return b.do(ctx, func(page *rod.Page) error {
+		return b.moveMouse(page, x, y)
+	})
 }
 
 // Click clicks at the specified coordinates with the specified button
-func (b *Browser) Click(x, y int, button string) {
-	mouse := b.page.Mouse
-	mouse.MustMoveTo(float64(x), float64(y))
+func (b *Browser) Click(ctx context.Context, x, y int, button string) error {
+	return b.do(ctx, func(page *rod.Page) error {
+		mouse := page.Mouse
+		if err := b.moveMouse(page, x, y); err != nil {
+			return err
+		}
 
-	switch button {
-	case "right":
-		mouse.MustDown("right")
-		mouse.MustUp("right")
-	default: // "left" is default
-		mouse.MustDown("left")
-		mouse.MustUp("left")
-	}
-	b.page.MustWaitStable()
+		btn := proto.InputMouseButtonLeft // "left" is default
+		if button == "right" {
+			btn = proto.InputMouseButtonRight
+		}
+		if err := mouse.Down(btn, 1); err != nil {
+			return err
+		}
+		if err := mouse.Up(btn, 1); err != nil {
+			return err
+		}
+		return settle(page, time.Second)
+	})
 }
 
 // DoubleClick double-clicks at the specified coordinates
-func (b *Browser) DoubleClick(x, y int) {
-	mouse := b.page.Mouse
-	mouse.MustMoveTo(float64(x), float64(y))
-	mouse.MustClick("left")
-	mouse.MustClick("left")
-	b.page.MustWaitStable()
+func (b *Browser) DoubleClick(ctx context.Context, x, y int) error {
+	return b.do(ctx, func(page *rod.Page) error {
+		mouse := page.Mouse
+		if err := b.moveMouse(page, x, y); err != nil {
+			return err
+		}
+		if err := mouse.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return err
+		}
+		if err := mouse.Click(proto.InputMouseButtonLeft, 2); err != nil {
+			return err
+		}
+		return settle(page, time.Second)
+	})
 }
 
 // Scroll scrolls the page at the specified coordinates
-func (b *Browser) Scroll(x, y, scrollX, scrollY int) {
-	mouse := b.page.Mouse
-	mouse.MustMoveTo(float64(x), float64(y))
-	b.page.Mouse.MustScroll(float64(scrollX), float64(scrollY))
-	b.page.MustWaitStable()
+func (b *Browser) Scroll(ctx context.Context, x, y, scrollX, scrollY int) error {
+	return b.do(ctx, func(page *rod.Page) error {
+		mouse := page.Mouse
+		if err := b.moveMouse(page, x, y); err != nil {
+			return err
+		}
+		if err := mouse.Scroll(float64(scrollX), float64(scrollY), 1); err != nil {
+			return err
+		}
+		return settle(page, time.Second)
+	})
 }
 
 // Wait waits for the specified number of milliseconds
-func (b *Browser) Wait(ms int) {
-	time.Sleep(time.Duration(ms) * time.Millisecond)
+func (b *Browser) Wait(ctx context.Context, ms int) error {
+	return sleepContext(ctx, time.Duration(ms)*time.Millisecond)
+}
+
+// Drag presses the left mouse button at the first point of path, moves
+// through the remaining points and releases it at the last one
+func (b *Browser) Drag(ctx context.Context, path []Point) error {
+	if len(path) == 0 {
+		return fmt.Errorf("drag path is empty")
+	}
+	return b.do(ctx, func(page *rod.Page) error {
+		mouse := page.Mouse
+		if err := b.moveMouse(page, path[0].X, path[0].Y); err != nil {
+			return err
+		}
+		if err := mouse.Down(proto.InputMouseButtonLeft, 1); err != nil {
+			return err
+		}
+		for _, p := range path[1:] {
+			// Intermediate steps let pages see a continuous drag
+			if err := mouse.MoveLinear(proto.Point{X: float64(p.X), Y: float64(p.Y)}, 5); err != nil {
+				return err
+			}
+		}
+		if err := mouse.Up(proto.InputMouseButtonLeft, 1); err != nil {
+			return err
+		}
+		return settle(page, time.Second)
+	})
+}
+
+// ScrollCondition describes when ScrollUntil should stop scrolling
+type ScrollCondition struct {
+	Selector   string `json:"selector,omitempty"`
+	MinItems   int    `json:"min_items,omitempty"`
+	Text       string `json:"text,omitempty"`
+	MaxScrolls int    `json:"max_scrolls,omitempty"`
+}
+
+// ScrollResult reports the page state when ScrollUntil stopped
+type ScrollResult struct {
+	Reason  string `json:"reason"`
+	Scrolls int    `json:"scrolls"`
+	Items   int    `json:"items"`
 }
 
-// Drag performs a drag operation along the specified path
-func (b *Browser) Drag(path []map[string]int) {
-	fmt.Println("Drag not implemented")
+// scrollStateJS reports the number of matching items, whether the text is present and whether the page is at the bottom
+const scrollStateJS = `(selector, text) => {
+	const items = selector ? document.querySelectorAll(selector).length : 0;
+	const found = text ? document.body.innerText.includes(text) : false;
+	const el = document.scrollingElement || document.documentElement;
+	const bottom = el.scrollTop + window.innerHeight >= el.scrollHeight - 2;
+	return {items, found, bottom};
+}`
+
+// scrollLimit caps MaxScrolls, which the model chooses
+const scrollLimit = 50
+
+// ScrollUntil scrolls down one viewport at a time until the condition is met,
+// the bottom of the page is reached or MaxScrolls steps (20 by default, at
+// most 50) have been taken
+func (b *Browser) ScrollUntil(ctx context.Context, cond ScrollCondition) (*ScrollResult, error) {
+	maxScrolls := cond.MaxScrolls
+	if maxScrolls <= 0 {
+		maxScrolls = 20
+	}
+	maxScrolls = min(maxScrolls, scrollLimit)
+
+	page := b.activePage().Context(ctx)
+	mouse := page.Mouse
+	if err := mouse.MoveTo(proto.Point{X: float64(b.width) / 2, Y: float64(b.height) / 2}); err != nil {
+		return nil, fmt.Errorf("error moving mouse: %w", err)
+	}
+
+	result := &ScrollResult{}
+	for {
+		obj, err := page.Eval(scrollStateJS, cond.Selector, cond.Text)
+		if err != nil {
+			return nil, fmt.Errorf("error reading scroll state: %w", err)
+		}
+		var state struct {
+			Items  int  `json:"items"`
+			Found  bool `json:"found"`
+			Bottom bool `json:"bottom"`
+		}
+		if err := obj.Value.Unmarshal(&state); err != nil {
+			return nil, fmt.Errorf("error decoding scroll state: %w", err)
+		}
+		result.Items = state.Items
+
+		switch {
+		case cond.Selector != "" && cond.MinItems > 0 && state.Items >= cond.MinItems:
+			result.Reason = "min_items"
+		case cond.Text != "" && state.Found:
+			result.Reason = "text_found"
+		case state.Bottom:
+			result.Reason = "bottom"
+		case result.Scrolls >= maxScrolls:
+			result.Reason = "max_scrolls"
+		}
+		if result.Reason != "" {
+			return result, nil
+		}
+
+		if err := mouse.Scroll(0, float64(b.height), 1); err != nil {
+			return nil, fmt.Errorf("error scrolling: %w", err)
+		}
+		result.Scrolls++
+		if err := settle(page, 500*time.Millisecond); err != nil {
+			return nil, err
+		}
+	}
 }
@@ -3,8 +3,12 @@ package computeruse
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -13,109 +17,676 @@ import (
 // Parameters:
 // - url: The URL to open in the browser
 // - instruction: The instruction to send to the AI model
+// - opts: Optional settings such as WithScrollHelper
 // Returns an error if any operation fails
-func BrowserUse(ctx context.Context, url, instruction string, maxTurns int) error {
-	model := "computer-use-preview-2025-03-11"
+func BrowserUse(ctx context.Context, url, instruction string, maxTurns int, opts ...Option) error {
+	return NewClient().BrowserUse(ctx, url, instruction, maxTurns, opts...)
+}
+
+// BrowserUseResult opens url in a new browser, runs the instruction against it
+// and returns the structured result: the final answer, the actions taken with
+// their screenshots, the token usage and the terminal status
+func BrowserUseResult(ctx context.Context, url, instruction string, maxTurns int, opts ...Option) (*Result, error) {
+	return NewClient().BrowserUseResult(ctx, url, instruction, maxTurns, opts...)
+}
+
+// browserUseResult launches a browser of the configured display size at url and runs the instruction
+func browserUseResult(ctx context.Context, url, instruction string, maxTurns int, opts ...Option) (*Result, error) {
+	// Validate the settings before paying for a browser launch
+	cfg := newConfig(opts)
+	if err := errors.Join(cfg.errs...); err != nil {
+		return nil, err
+	}
+	scale := cfg.screenScale(cfg.displayWidth, cfg.displayHeight)
+	if err := checkModel(cfg.model, cfg.environmentOr("browser"), scale.width, scale.height); err != nil {
+		return nil, err
+	}
 
-	browser := NewBrowser(1024, 768)
-	err := browser.Open(url)
+	browser, err := LaunchBrowser(ctx, opts...)
 	if err != nil {
-		return fmt.Errorf("error opening browser: %w", err)
+		return nil, err
 	}
+	if cfg.keepProfile {
+		browser.KeepProfile()
+		cfg.events.notice("📁 Browser profile kept at " + browser.ProfileDir())
+	}
+	return runBrowser(ctx, browser, cfg, url, instruction, maxTurns, opts)
+}
+
+// runBrowser opens url in browser, runs the instruction there and closes the browser
+func runBrowser(ctx context.Context, browser *Browser, cfg *config, url, instruction string, maxTurns int, opts []Option) (*Result, error) {
 	defer browser.Close()
+	if len(cfg.cookies) > 0 {
+		if err := browser.SetCookies(ctx, cfg.cookies); err != nil {
+			return nil, err
+		}
+	}
+	if err := browser.Open(ctx, url); err != nil {
+		return nil, fmt.Errorf("error opening browser: %w", err)
+	}
+
+	opts = append(opts[:len(opts):len(opts)], withReset(func(ctx context.Context) error {
+		return browser.Navigate(ctx, url)
+	}))
+	return Run(ctx, browser, instruction, maxTurns, opts...)
+}
+
+// ComputerUse runs the computer-use loop against any Computer, such as a
+// Browser or the native Desktop
+func ComputerUse(ctx context.Context, computer Computer, instruction string, maxTurns int, opts ...Option) error {
+	_, err := Run(ctx, computer, instruction, maxTurns, opts...)
+	return err
+}
+
+// runAttempt runs the computer-use loop once for an instruction
+func runAttempt(ctx context.Context, computer Computer, instruction string, maxTurns int, cfg *config, rec *artifactRecorder) (*Result, error) {
+	// The model sees the downscaled display; its coordinates are mapped back in computerCall
+	scale := cfg.screenScale(computer.Dimensions())
+	tools := []Tool{
+		{
+			Type:          "computer-preview",
+			DisplayWidth:  scale.width,
+			DisplayHeight: scale.height,
+			Environment:   cfg.environmentOr(computer.Environment()),
+		},
+	}
+	for _, t := range cfg.tools {
+		tools = append(tools, t.tool)
+	}
+	switchable := slices.ContainsFunc(cfg.tools, func(t functionTool) bool {
+		return t.tool.Name == switchTabTool.tool.Name
+	})
+
+	nav := newNavigationTracker(cfg.loopWarnAfter, cfg.loopAbortAfter)
+	stuck := newStuckDetector(cfg.stuckRepeats)
+	var lastFrame string
+	uploaded := uploadedFiles{}
+	guard := newNavigationGuard(cfg.navigationPolicy)
+	if u, ok := computer.(urlReporter); ok {
+		guard.check(u.GetCurrentUrl())
+	}
+	validators := cloneValidators(cfg.validators)
+	result := &Result{Status: StatusMaxTurns}
+
+	task := instruction
+	for _, extra := range cfg.instructions {
+		instruction += "\n\n" + extra
+	}
 
 	var responseID string
-	var callID string
-	var callResp *ComputerOutput
+	var partialOutput string
+	// stalled is set after an incomplete response without output
+	var stalled bool
+	var turnOffset int
+	messages := append(slices.Clone(cfg.context), Input{
+		Role:    "user",
+		Content: instruction,
+	})
+	if cp := cfg.resume; cp != nil {
+		// Continue the saved conversation instead of starting a new one
+		responseID = cp.PreviousResponseID
+		messages = slices.Clone(cp.Pending)
+		turnOffset = cp.Turn
+		if cfg.memory != nil {
+			for k, v := range cp.Memory {
+				cfg.memory.Set(k, v)
+			}
+		}
+		cfg.resume = nil
+	}
+
+	var lastCallID string
+	saveSession := func(status Status, output string) {
+		if cfg.sessionFile == "" {
+			return
+		}
+		session := &Session{
+			Model:              cfg.model,
+			Instruction:        task,
+			MaxTurns:           turnOffset + maxTurns,
+			Turn:               turnOffset + result.Turns,
+			PreviousResponseID: responseID,
+			LastCallID:         lastCallID,
+			Screenshot:         rec.lastFile(),
+			Pending:            messages,
+			Tags:               cfg.tags,
+			Memory:             cfg.memory.snapshot(),
+			Status:             status,
+			Output:             output,
+			UpdatedAt:          time.Now(),
+		}
+		if u, ok := computer.(urlReporter); ok {
+			session.CurrentURL = u.GetCurrentUrl()
+		}
+		if err := SaveSession(cfg.sessionFile, session); err != nil {
+			cfg.events.error(err)
+		}
+	}
+
+	// Each turn runs under its own context, bounded by WithTurnTimeout
+	runCtx := ctx
+	cancelTurn := context.CancelFunc(func() {})
+	defer func() {
+		cancelTurn()
+		// Errors caused by canceling the run are reported as a cancellation
+		if runCtx.Err() != nil && result.Status == StatusFailed {
+			result.Status = StatusCanceled
+		}
+		if cfg.memory != nil {
+			result.Memory = cfg.memory.snapshot()
+			data, _ := json.Marshal(result.Memory)
+			cfg.events.notice("🧠 Memory: " + string(data))
+		}
+	}()
 
 	for i := 0; i < maxTurns; i++ {
 		select {
-		case <-ctx.Done():
-			return fmt.Errorf("context canceled")
+		case <-runCtx.Done():
+			result.Status = StatusCanceled
+			return result, fmt.Errorf("context canceled: %w", runCtx.Err())
 		default:
 		}
-
-		messages := []Input{}
-		if responseID == "" {
-			messages = append(messages, Input{
-				Role:    "user",
-				Content: instruction,
-			})
-		} else {
-			messages = append(messages, Input{
-				Type:   "computer_call_output",
-				CallID: callID,
-				Output: callResp,
-			})
+		if err := cfg.spent.check(); err != nil {
+			result.Status = StatusBudgetExceeded
+			return result, err
 		}
-
-		debugInput(messages)
-		response, err := Responses(model, responseID, messages)
+		cancelTurn()
+		ctx, cancelTurn = cfg.turnContext(runCtx)
+		result.Turns++
+		rec.beginTurn()
+		// The display may have been resized during the previous turn
+		scale = cfg.screenScale(computer.Dimensions())
+		tools[0].DisplayWidth, tools[0].DisplayHeight = scale.width, scale.height
+
+		cfg.events.turnStart(result.Turns, messages)
+		request := Request{
+			Model:              cfg.model,
+			Input:              messages,
+			Tools:              tools,
+			MaxOutputTokens:    cfg.maxOutputTokens,
+			Truncation:         cfg.truncation,
+			PreviousResponseID: responseID,
+			Reasoning:          reasoningParams(cfg),
+			Text:               cfg.outputFormat,
+			Metadata:           cfg.tags,
+		}
+		if err := cfg.trace.request(request); err != nil {
+			cfg.events.error(err)
+		}
+		response, err := createResponse(ctx, cfg, request)
 		if err != nil {
-			return fmt.Errorf("error calling OpenAI API: %w", err)
+			result.Status = StatusFailed
+			err = fmt.Errorf("error calling OpenAI API: %w", err)
+			if cfg.resilience == Resilient && cfg.checkpointPath != "" {
+				// Save the task as given; the instructions are appended again on resume
+				cp := &Checkpoint{
+					Model:              cfg.model,
+					Instruction:        task,
+					PreviousResponseID: responseID,
+					Pending:            messages,
+					Turn:               turnOffset + i,
+					Tags:               cfg.tags,
+					Memory:             cfg.memory.snapshot(),
+				}
+				if u, ok := computer.(urlReporter); ok {
+					cp.CurrentURL = u.GetCurrentUrl()
+				}
+				if serr := SaveCheckpoint(cfg.checkpointPath, cp); serr != nil {
+					return result, errors.Join(err, serr)
+				}
+				return result, &CheckpointError{Path: cfg.checkpointPath, Err: err}
+			}
+			return result, err
+		}
+		cfg.events.response(result.Turns, response)
+		if err := cfg.trace.response(response); err != nil {
+			cfg.events.error(err)
 		}
-		debugResponse(response)
 
 		responseID = response.ID
+		result.ResponseID = response.ID
+		messages = nil
+		result.Usage = result.Usage.add(response.Usage)
+		cfg.spent.add(response.Usage)
+		rec.setReasoning(reasoningSummary(response))
 
 		finalOutput := ""
+		var failures []Input
 		for _, o := range response.Output {
 			if o.Action != nil {
-				var err error
-				callResp, err = computerCall(browser, o.Action)
+				lastCallID = o.CallID
+				var currentURL string
+				if u, ok := computer.(urlReporter); ok {
+					currentURL = u.GetCurrentUrl()
+				}
+				acknowledged, records, err := resolveSafetyChecks(ctx, cfg, o, result.Turns, currentURL, rec.lastFile())
+				result.SafetyChecks = append(result.SafetyChecks, records...)
 				if err != nil {
-					return fmt.Errorf("error executing browser action: %w", err)
+					result.Status = StatusFailed
+					return result, err
+				}
+
+				cfg.events.action(result.Turns, *o.Action)
+				cfg.observer.action(result.Turns, *o.Action)
+				callResp, err := computerCall(ctx, computer, o.Action, cfg, scale, lastFrame)
+				record := ActionRecord{Turn: result.Turns, Action: *o.Action}
+				var actionErr *ActionError
+				if errors.As(err, &actionErr) {
+					record.Error = actionErr.Err.Error()
+					// Tell the model about the failure so it can try something else
+					failures = append(failures, Input{
+						Role:    "user",
+						Content: fmt.Sprintf("The last %s action did not complete: %v", actionErr.Action, actionErr.Err),
+					})
+				} else if err != nil {
+					result.Status = StatusFailed
+					return result, fmt.Errorf("error executing browser action: %w", err)
+				}
+				if guard.check(callResp.CurrentURL) {
+					cfg.events.notice("⛔ Blocked navigation to " + callResp.CurrentURL)
+					message, err := guard.enforce(ctx, computer, cfg, scale, callResp)
+					if err != nil {
+						result.Status = StatusFailed
+						return result, err
+					}
+					failures = append(failures, Input{
+						Role:    "user",
+						Content: message,
+					})
+				}
+				previousFrame := lastFrame
+				lastFrame = callResp.ImageURL
+				stem := rec.stem(o.Action.Type)
+				record.URL = callResp.CurrentURL
+				record.Element = callResp.Element
+				if file, err := saveScreenshot(callResp, stem); err != nil {
+					cfg.events.error(err)
+				} else {
+					record.Screenshot = file
+					cfg.events.screenshot(result.Turns, file, callResp)
+					if err := rec.addFrame(o.Action.Type, file, callResp.CurrentURL, callResp.Element); err != nil {
+						cfg.events.error(err)
+					}
+				}
+				cfg.observer.frame(result.Turns, record.Screenshot, callResp)
+				var traceErr error
+				if actionErr != nil {
+					traceErr = actionErr.Err
+				}
+				if err := cfg.trace.action(*o.Action, scale.toScreen(*o.Action), callResp, traceErr); err != nil {
+					cfg.events.error(err)
+				}
+				result.Actions = append(result.Actions, record)
+				if cfg.domSnapshots {
+					if file, err := saveDOMSnapshot(ctx, computer, stem, cfg.actionTimeout); err != nil {
+						cfg.events.error(err)
+					} else if file != "" {
+						cfg.events.notice("🧾 DOM snapshot saved: " + file)
+					}
+				}
+				messages = append(messages, NewComputerCallOutput(o.CallID, callResp, acknowledged...))
+				if callResp.Page != nil {
+					messages = append(messages, Input{
+						Role:    "user",
+						Content: pageMetadataMessage(callResp.Page),
+					})
+				}
+				if callResp.Content != nil {
+					messages = append(messages, Input{
+						Role:    "user",
+						Content: pageContentMessage(callResp.Content),
+					})
+				}
+				if t := callResp.Tab; t != nil && (t.Count > 1 || t.Opened) {
+					messages = append(messages, Input{
+						Role:    "user",
+						Content: tabMessage(t, switchable),
+					})
+				}
+				if b, ok := computer.(*Browser); ok {
+					for _, d := range b.finishedDownloads() {
+						messages = append(messages, Input{
+							Role:    "user",
+							Content: fmt.Sprintf("The file %s finished downloading.", d.Filename),
+						})
+					}
+					if len(cfg.uploadFiles) > 0 && b.FileChooserOpen() {
+						messages = append(messages, Input{
+							Role:    "user",
+							Content: fileChooserMessage(cfg.uploadFiles),
+						})
+					}
 				}
-				callID = o.CallID
-				if len(o.PendingSafetyChecks) > 0 {
-					fmt.Println("pending safety checks:", o.PendingSafetyChecks)
+				if callResp.Viewport != nil {
+					messages = append(messages, Input{
+						Role:    "user",
+						Content: viewportMessage(callResp.Viewport),
+					})
+				}
+				messages = append(messages, failures...)
+				failures = nil
+
+				warning, err := nav.visit(callResp.CurrentURL)
+				if err != nil {
+					result.Status = StatusFailed
+					return result, err
+				}
+				if warning != "" {
+					messages = append(messages, Input{
+						Role:    "user",
+						Content: warning,
+					})
+				}
+				if nudge := stuck.observe(o.Action, callResp); nudge != "" {
+					messages = append(messages, Input{
+						Role:    "user",
+						Content: nudge,
+					})
+				}
+				unchanged, err := cfg.screenshots.prepare(callResp, previousFrame)
+				if err != nil {
+					result.Status = StatusFailed
+					return result, err
+				}
+				if unchanged {
+					messages = append(messages, Input{
+						Role:    "user",
+						Content: noChangeMessage,
+					})
+				}
+				if cfg.uploadScreenshots {
+					if err := uploadScreenshot(ctx, cfg.endpoint(), callResp, uploaded); err != nil {
+						result.Status = StatusFailed
+						return result, err
+					}
 				}
-				debugComputerOutput(callResp)
+			}
+			if o.Type == "function_call" {
+				output, err := callFunctionTool(ctx, cfg.tools, computer, o.Name, o.Arguments, cfg.actionTimeout)
+				record := ActionRecord{Turn: result.Turns, Action: Action{Type: "function_call"}}
+				if err != nil {
+					// Report the failure to the model so it can try something else
+					output = "error: " + err.Error()
+					record.Error = err.Error()
+				}
+				record.Function = &FunctionCallRecord{Name: o.Name, Arguments: o.Arguments, Output: output}
+				if err := cfg.trace.function(*record.Function, err); err != nil {
+					cfg.events.error(err)
+				}
+				result.Actions = append(result.Actions, record)
+				messages = append(messages, NewFunctionCallOutput(o.CallID, output))
 			}
 			if o.Content != nil {
 				if o.Role == "assistant" {
-					finalOutput = fmt.Sprint(o.Content[0])
+					finalOutput = o.Text()
+					if finalOutput == "" {
+						finalOutput = fmt.Sprint(o.Content[0])
+					}
+					cfg.events.assistantMessage(result.Turns, finalOutput)
 					break
 				}
 			}
 		}
 
+		// An incomplete response without any output, e.g. when reasoning used up
+		// max_output_tokens, leaves nothing to send back: ask the model to go
+		// on once, and give up when it stalls again
+		if response.Status == "incomplete" && finalOutput == "" && len(messages) == 0 {
+			if stalled {
+				result.Status = StatusFailed
+				return result, fmt.Errorf("response incomplete (%s) without output twice in a row; consider raising WithMaxOutputTokens",
+					response.IncompleteReason())
+			}
+			stalled = true
+			messages = append(messages, Input{
+				Role:    "user",
+				Content: "Your response was cut off before you took an action or answered. Continue with the task.",
+			})
+			continue
+		}
+		stalled = false
+
+		// The answer was cut off by max_output_tokens, ask for the rest of it
+		if finalOutput != "" && response.IncompleteReason() == "max_output_tokens" {
+			partialOutput += finalOutput
+			messages = append(messages, Input{
+				Role:    "user",
+				Content: "Your answer was cut off. Continue it exactly where it stopped, without repeating what you already wrote.",
+			})
+			continue
+		}
+		if finalOutput != "" {
+			finalOutput = partialOutput + finalOutput
+			partialOutput = ""
+		}
+
+		if cfg.steering != "" && len(messages) > 0 {
+			messages = append(messages, Input{
+				Role:    "user",
+				Content: cfg.steering,
+			})
+		}
+
+		if finalOutput != "" {
+			repair, err := validateAnswer(validators, finalOutput)
+			if err != nil {
+				result.Output = finalOutput
+				result.Status = StatusFailed
+				return result, err
+			}
+			if repair != "" {
+				messages = append(messages, Input{
+					Role:    "user",
+					Content: repair,
+				})
+				continue
+			}
+		}
+
 		if finalOutput != "" {
-			fmt.Println("Final output:", finalOutput)
+			cfg.events.notice("Final output: " + finalOutput)
+			result.Output = finalOutput
+			result.Status = StatusCompleted
+			saveSession(StatusCompleted, finalOutput)
 			break
 		}
-		time.Sleep(1 * time.Second)
+		if cfg.turnBudget && len(messages) > 0 {
+			if hint := turnBudgetHint(maxTurns-result.Turns, cfg.wrapUp); hint != "" {
+				messages = append(messages, Input{
+					Role:    "user",
+					Content: hint,
+				})
+			}
+		}
+		saveSession(StatusMaxTurns, "")
+		if err := sleepContext(runCtx, time.Second); err != nil {
+			result.Status = StatusCanceled
+			return result, fmt.Errorf("context canceled: %w", err)
+		}
 	}
 
-	return nil
+	return result, nil
 }
 
-// computerCall executes a browser action and returns the resulting output
-func computerCall(b *Browser, action *Action) (*ComputerOutput, error) {
+// turnBudgetHint tells the model how many turns remain, and with wrapUp asks
+// for the final answer when only one is left
+func turnBudgetHint(remaining int, wrapUp bool) string {
+	switch {
+	case remaining <= 0:
+		return ""
+	case remaining == 1 && wrapUp:
+		return "This is your last turn. Stop using the computer and give your final answer now, " +
+			"including what you found so far if the task is not finished."
+	case remaining == 1:
+		return "You have 1 turn left."
+	}
+	return fmt.Sprintf("You have %d turns left.", remaining)
+}
+
+// ActionError reports a browser action that failed or timed out. The screenshot
+// taken afterwards is still returned so the failure can be reported to the model.
+type ActionError struct {
+	Action string
+	Err    error
+}
+
+func (e *ActionError) Error() string {
+	return fmt.Sprintf("%s action failed: %v", e.Action, e.Err)
+}
+
+func (e *ActionError) Unwrap() error {
+	return e.Err
+}
+
+// computerCall executes a browser action and returns the resulting output.
+// The action and the screenshot each run with their own timeout, separated by
+// the configured post-action delay.
+func computerCall(ctx context.Context, c Computer, action *Action, cfg *config, scale screenScale, lastFrame string) (*ComputerOutput, error) {
+	timeout := cfg.actionTimeout
+	// Malformed actions are not executed; the model is told why instead
+	actionErr := action.ValidateFor(scale.width, scale.height)
+	screenAction := scale.toScreen(*action)
+	// Record what is under the pointer before the click changes the page
+	var element *ElementInfo
+	if b, ok := c.(*Browser); ok && actionErr == nil && (action.Type == "click" || action.Type == "double_click") {
+		ectx, cancel := context.WithTimeout(ctx, timeout)
+		element, _ = b.ElementAt(ectx, screenAction.X, screenAction.Y)
+		cancel()
+	}
+	if actionErr == nil {
+		// Ask before destructive actions; a denial is reported to the model
+		actionErr = cfg.approve(ctx, c, &screenAction, element)
+	}
+	if actionErr == nil {
+		actx, cancel := context.WithTimeout(ctx, timeout)
+		actionErr = performAction(actx, c, &screenAction)
+		cancel()
+	}
+	if actionErr != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// Give slow pages time to react before the screenshot
+	if delay := cfg.actionDelay(action.Type); delay > 0 {
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+
+	if action.Type == "resize" && actionErr == nil {
+		// Keep the aspect ratio of the new display in the screenshot
+		scale = cfg.screenScale(c.Dimensions())
+	}
+
+	sctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	out := &ComputerOutput{
+		Type:    "input_image",
+		Element: element,
+	}
+	// Actions that leave the screen unchanged may reuse the previous frame
+	if lastFrame != "" && actionErr == nil && cfg.reuseScreenshot[action.Type] {
+		out.ImageURL = lastFrame
+		out.reused = true
+	} else {
+		screenshot, err := privateScreenshot(sctx, c, cfg.privacySelectors)
+		if err != nil {
+			return nil, err
+		}
+		if screenshot, err = scale.shrink(screenshot); err != nil {
+			return nil, err
+		}
+		out.ImageURL = dataURL(screenshot)
+	}
+	if u, ok := c.(urlReporter); ok {
+		out.CurrentURL = u.GetCurrentUrl()
+	}
+	if b, ok := c.(*Browser); ok {
+		// Not fatal: the screenshot alone is still usable
+		if cfg.viewportInfo {
+			if viewport, err := b.Viewport(sctx); err == nil {
+				out.Viewport = viewport
+			}
+		}
+		if cfg.pageMetadata {
+			if meta, err := b.Metadata(sctx); err == nil {
+				out.Page = meta
+			}
+		}
+		if tab, err := b.ActiveTab(sctx); err == nil {
+			out.Tab = tab
+		}
+		if cfg.pageContext != nil {
+			if content, err := b.PageContent(sctx, *cfg.pageContext, cfg.privacySelectors); err == nil {
+				out.Content = content
+			}
+		}
+	}
+	if actionErr != nil {
+		return out, &ActionError{Action: action.Type, Err: actionErr}
+	}
+	return out, nil
+}
+
+// performAction dispatches an action to the computer
+func performAction(ctx context.Context, b Computer, action *Action) error {
 	switch action.Type {
 	case "screenshot":
 		// Just take a screenshot, no additional action needed
 	case "type":
-		b.Type(action.Text)
+		return b.Type(ctx, action.Text)
 	case "click":
-		b.Click(action.X, action.Y, action.Button)
+		return b.Click(ctx, action.X, action.Y, action.Button)
+	case "double_click":
+		return b.DoubleClick(ctx, action.X, action.Y)
+	case "move":
+		return b.Move(ctx, action.X, action.Y)
 	case "scroll":
-		b.Scroll(action.X, action.Y, action.ScrollX, action.ScrollY)
+		return b.Scroll(ctx, action.X, action.Y, action.ScrollX, action.ScrollY)
 	case "keypress":
-		b.Keypress(action.Keys)
+		return b.Keypress(ctx, action.Keys)
 	case "wait":
-		time.Sleep(3 * time.Second)
+		return b.Wait(ctx, 3000)
+	case "drag":
+		if d, ok := b.(dragger); ok {
+			return d.Drag(ctx, action.Path)
+		}
+		return &UnsupportedActionError{Type: action.Type, Environment: b.Environment()}
+	case "goto":
+		if n, ok := b.(navigator); ok {
+			return n.Navigate(ctx, action.URL)
+		}
+		return &UnsupportedActionError{Type: action.Type, Environment: b.Environment()}
+	case "resize":
+		if r, ok := b.(resizer); ok {
+			return r.Resize(ctx, action.Width, action.Height)
+		}
+		return &UnsupportedActionError{Type: action.Type, Environment: b.Environment()}
+	case "switch_tab":
+		if t, ok := b.(tabSwitcher); ok {
+			return t.SwitchTab(ctx, action.Tab)
+		}
+		return &UnsupportedActionError{Type: action.Type, Environment: b.Environment()}
+	default:
+		return &UnsupportedActionError{Type: action.Type}
 	}
+	return nil
+}
 
-	screenshot, err := b.Screenshot()
-	if err != nil {
-		return nil, fmt.Errorf("error taking screenshot: %w", err)
+// viewportMessage tells the model where the viewport is within the page
+func viewportMessage(v *ViewportState) string {
+	below := max(0, v.PageHeight-v.ScrollY-v.Height)
+	return fmt.Sprintf("Viewport: scrolled to (%d, %d), showing %dx%d of a %dx%d page; %dpx of the page remain below the fold.",
+		v.ScrollX, v.ScrollY, v.Width, v.Height, v.PageWidth, v.PageHeight, below)
+}
+
+// pageMetadataMessage gives the model signals such as error statuses that are not always visible
+func pageMetadataMessage(p *PageMetadata) string {
+	status := "unknown"
+	if p.HTTPStatus > 0 {
+		status = fmt.Sprint(p.HTTPStatus)
 	}
-	return &ComputerOutput{
-		Type:       "input_image",
-		ImageURL:   dataURL(screenshot),
-		CurrentURL: b.GetCurrentUrl(),
-	}, nil
+	return fmt.Sprintf("Page: title %q, HTTP status %s, load state %s.", p.Title, status, p.LoadState)
 }
 
 // dataURL converts binary data to a base64-encoded data URL
@@ -123,6 +694,15 @@ func dataURL(data []byte) string {
 	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
 }
 
+// decodeDataURL returns the binary data of a base64-encoded data URL
+func decodeDataURL(dataurl string) ([]byte, error) {
+	_, database64, ok := strings.Cut(dataurl, ",")
+	if !ok {
+		return nil, fmt.Errorf("invalid data URL")
+	}
+	return base64.StdEncoding.DecodeString(database64)
+}
+
 // debugResponse formats and displays Response details
 func debugResponse(response *Response) {
 	fmt.Println("\n📩 ----- RESPONSE DETAILS -----")
@@ -166,6 +746,21 @@ func debugResponse(response *Response) {
 				fmt.Println("  --------------------------")
 			}
 
+			if o.Type == "reasoning" && len(o.Summary) > 0 {
+				fmt.Println("💭 ----- REASONING SUMMARY -----")
+				for _, p := range o.Summary {
+					fmt.Printf("  %s\n", p.Text)
+				}
+				fmt.Println("  -----------------------------")
+			}
+
+			if o.Type == "function_call" {
+				fmt.Println("🛠️ ----- FUNCTION CALL -----")
+				fmt.Printf("  Name: %s\n", o.Name)
+				fmt.Printf("  Arguments: %s\n", o.Arguments)
+				fmt.Println("  --------------------------")
+			}
+
 			if o.Content != nil && o.Role == "assistant" {
 				fmt.Println("🤖 ----- ASSISTANT RESPONSE -----")
 				for j, content := range o.Content {
@@ -184,36 +779,32 @@ func debugResponse(response *Response) {
 		}
 	}
 
-	fmt.Println("📩 ----- END OF RESPONSE DETAILS -----\n")
+	fmt.Println("📩 ----- END OF RESPONSE DETAILS -----")
+	fmt.Println()
 }
 
-// debugComputerOutput saves the screenshot from ComputerOutput to a file
-func debugComputerOutput(out *ComputerOutput) {
-	dataurl := out.ImageURL
-	if dataurl == "" {
-		fmt.Println("📷 No screenshot available")
-		return
+// saveScreenshot saves the screenshot from ComputerOutput to a file named stem.png
+func saveScreenshot(out *ComputerOutput, stem string) (string, error) {
+	if out.ImageURL == "" {
+		return "", fmt.Errorf("no screenshot available")
 	}
 
-	database64 := strings.Split(dataurl, ",")[1]
-	data, err := base64.StdEncoding.DecodeString(database64)
+	data, err := decodeDataURL(out.ImageURL)
 	if err != nil {
-		fmt.Printf("❌ Error decoding screenshot: %v\n", err)
-		return
+		return "", fmt.Errorf("error decoding screenshot: %w", err)
 	}
 
-	// Create filename with timestamp
-	os.MkdirAll("screenshots", 0755)
-	filename := fmt.Sprintf("screenshots/%s.png", time.Now().Format("20060102150405"))
-
-	// Save the file
-	err = os.WriteFile(filename, data, 0644)
-	if err != nil {
-		fmt.Printf("❌ Error saving screenshot: %v\n", err)
-		return
+	os.MkdirAll(filepath.Dir(stem), 0755)
+	filename := stem + ".png"
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", fmt.Errorf("error saving screenshot: %w", err)
 	}
+	return filename, nil
+}
 
-	fmt.Printf("📷 Screenshot saved: %s\n", filename)
+// debugScreenshot prints where a screenshot was saved and the state it shows
+func debugScreenshot(file string, out *ComputerOutput) {
+	fmt.Printf("📷 Screenshot saved: %s\n", file)
 
 	// Log browser state if available
 	if out.CurrentURL != "" {
@@ -224,6 +815,26 @@ func debugComputerOutput(out *ComputerOutput) {
 	}
 }
 
+// saveDOMSnapshot saves an MHTML snapshot of the page next to its screenshot as stem.mhtml
+func saveDOMSnapshot(ctx context.Context, c Computer, stem string, timeout time.Duration) (string, error) {
+	b, ok := c.(*Browser)
+	if !ok {
+		return "", nil
+	}
+	sctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	data, err := b.Snapshot(sctx)
+	if err != nil {
+		return "", err
+	}
+
+	filename := stem + ".mhtml"
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return "", fmt.Errorf("error saving DOM snapshot: %w", err)
+	}
+	return filename, nil
+}
+
 // debugInput prints input message details for debugging
 func debugInput(input []Input) {
 	fmt.Println("\n📥 ----- INPUT MESSAGE DETAILS -----")
@@ -243,26 +854,30 @@ func debugInput(input []Input) {
 			fmt.Printf("  🔹 Call ID: %s\n", v.CallID)
 		}
 
-		if v.Content != "" {
-			contentPreview := v.Content
+		if text, ok := v.Content.(string); ok && text != "" {
+			contentPreview := text
 			if len(contentPreview) > 100 {
 				contentPreview = contentPreview[:97] + "..."
 			}
 			fmt.Printf("  🔹 Content: %s\n", contentPreview)
 		}
 
-		if v.Output != nil {
+		switch output := v.Output.(type) {
+		case *ComputerOutput:
 			fmt.Println("  🔹 Output details:")
-			if v.Output.CurrentURL != "" {
-				fmt.Printf("    - URL: %s\n", v.Output.CurrentURL)
+			if output.CurrentURL != "" {
+				fmt.Printf("    - URL: %s\n", output.CurrentURL)
 			}
-			if v.Output.Type != "" {
-				fmt.Printf("    - Type: %s\n", v.Output.Type)
+			if output.Type != "" {
+				fmt.Printf("    - Type: %s\n", output.Type)
 			}
+		case string:
+			fmt.Printf("  🔹 Output: %s\n", output)
 		}
 
 		fmt.Println("  ------------------------------")
 	}
 
-	fmt.Println("📥 ----- END OF INPUT DETAILS -----\n")
+	fmt.Println("📥 ----- END OF INPUT DETAILS -----")
+	fmt.Println()
 }
@@ -0,0 +1,109 @@
+package computeruse
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ModelPricing is the price of a model in US dollars per million tokens
+type ModelPricing struct {
+	Input       float64 `json:"input"`
+	CachedInput float64 `json:"cached_input,omitempty"`
+	Output      float64 `json:"output"`
+}
+
+// Cost returns the price of usage. Cached input tokens are charged at
+// CachedInput, or at Input when no cached price is set.
+func (p ModelPricing) Cost(u UsageInfo) float64 {
+	cached := u.InputTokensDetails.CachedTokens
+	cachedPrice := p.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = p.Input
+	}
+	return (float64(u.InputTokens-cached)*p.Input + float64(cached)*cachedPrice + float64(u.OutputTokens)*p.Output) / 1e6
+}
+
+// Budget limits what a run may consume. Zero limits are not enforced.
+type Budget struct {
+	MaxTokens int
+	// MaxCost is in US dollars, priced with Pricing or else the model registry
+	MaxCost     float64
+	MaxDuration time.Duration
+	// Pricing overrides the registered prices per model ID
+	Pricing map[string]ModelPricing
+}
+
+// ErrBudgetExceeded is matched by the error returned when a run stops because
+// it exceeded its Budget
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetExceededError reports the limit that was exceeded and what the run
+// consumed up to that point
+type BudgetExceededError struct {
+	// Limit is "tokens", "cost" or "duration"
+	Limit   string
+	Usage   UsageInfo
+	Cost    float64
+	Elapsed time.Duration
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%v: %s limit reached after %d tokens, $%.4f and %s",
+		ErrBudgetExceeded, e.Limit, e.Usage.TotalTokens, e.Cost, e.Elapsed.Round(time.Second))
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// budgetTracker accumulates the consumption of a run, across its attempts
+type budgetTracker struct {
+	budget  Budget
+	pricing ModelPricing
+	start   time.Time
+	usage   UsageInfo
+}
+
+// newBudgetTracker returns nil when no budget is configured, and fails when a
+// cost limit is set for a model without a known price
+func newBudgetTracker(budget *Budget, model string) (*budgetTracker, error) {
+	if budget == nil {
+		return nil, nil
+	}
+	t := &budgetTracker{budget: *budget, start: time.Now()}
+	if p, ok := budget.Pricing[model]; ok {
+		t.pricing = p
+	} else if info, ok := LookupModel(model); ok && info.Pricing != nil {
+		t.pricing = *info.Pricing
+	} else if budget.MaxCost > 0 {
+		return nil, fmt.Errorf("no pricing known for model %s to enforce the cost budget", model)
+	}
+	return t, nil
+}
+
+// add records the usage of a response
+func (t *budgetTracker) add(u UsageInfo) {
+	if t != nil {
+		t.usage = t.usage.add(u)
+	}
+}
+
+// check returns a *BudgetExceededError once any limit is reached
+func (t *budgetTracker) check() error {
+	if t == nil {
+		return nil
+	}
+	err := &BudgetExceededError{Usage: t.usage, Cost: t.pricing.Cost(t.usage), Elapsed: time.Since(t.start)}
+	switch {
+	case t.budget.MaxTokens > 0 && err.Usage.TotalTokens >= t.budget.MaxTokens:
+		err.Limit = "tokens"
+	case t.budget.MaxCost > 0 && err.Cost >= t.budget.MaxCost:
+		err.Limit = "cost"
+	case t.budget.MaxDuration > 0 && err.Elapsed >= t.budget.MaxDuration:
+		err.Limit = "duration"
+	default:
+		return nil
+	}
+	return err
+}
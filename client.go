@@ -0,0 +1,115 @@
+package computeruse
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// DefaultBaseURL is the OpenAI API endpoint used unless WithBaseURL or OPENAI_BASE_URL sets another
+const DefaultBaseURL = "https://api.openai.com/v1"
+
+// Client runs computer-use sessions with a fixed set of options, such as the
+// API key, endpoint and model. Options passed to its methods are applied on
+// top of the client's own.
+type Client struct {
+	opts []Option
+}
+
+// NewClient creates a client from options such as WithAPIKey, WithBaseURL,
+// WithHTTPClient, WithModel, WithDisplaySize and WithEnvironment
+func NewClient(opts ...Option) *Client {
+	return &Client{opts: opts}
+}
+
+// options returns the client options followed by the call options
+func (c *Client) options(opts []Option) []Option {
+	return append(c.opts[:len(c.opts):len(c.opts)], opts...)
+}
+
+// BrowserUse automates a browser opened at url; see the package-level BrowserUse
+func (c *Client) BrowserUse(ctx context.Context, url, instruction string, maxTurns int, opts ...Option) error {
+	_, err := c.BrowserUseResult(ctx, url, instruction, maxTurns, opts...)
+	return err
+}
+
+// BrowserUseResult automates a browser opened at url and returns the structured result
+func (c *Client) BrowserUseResult(ctx context.Context, url, instruction string, maxTurns int, opts ...Option) (*Result, error) {
+	return browserUseResult(ctx, url, instruction, maxTurns, c.options(opts)...)
+}
+
+// Run executes the instruction against a Computer; see the package-level Run
+func (c *Client) Run(ctx context.Context, computer Computer, instruction string, maxTurns int, opts ...Option) (*Result, error) {
+	return Run(ctx, computer, instruction, maxTurns, c.options(opts)...)
+}
+
+// Responses sends input to the model with the computer tool sized and typed by
+// WithDisplaySize and WithEnvironment, plus any additional function tools
+func (c *Client) Responses(ctx context.Context, responseID string, input []Input, tools ...Tool) (*Response, error) {
+	cfg := newConfig(c.opts)
+	if err := errors.Join(cfg.errs...); err != nil {
+		return nil, err
+	}
+	request := Request{
+		Model:              cfg.model,
+		Input:              input,
+		PreviousResponseID: responseID,
+		Truncation:         cfg.truncation,
+		Tools: append([]Tool{{
+			Type:          "computer-preview",
+			DisplayWidth:  cfg.displayWidth,
+			DisplayHeight: cfg.displayHeight,
+			Environment:   cfg.environmentOr("browser"),
+		}}, tools...),
+	}
+	return createResponse(ctx, cfg, request)
+}
+
+// endpoint is where and how API requests are sent
+type endpoint struct {
+	client  *http.Client
+	apiKey  string
+	baseURL string
+}
+
+// defaultEndpoint reads the API key and base URL from OPENAI_API_KEY and OPENAI_BASE_URL
+func defaultEndpoint() endpoint {
+	return endpoint{client: defaultHTTPClient()}.withDefaults()
+}
+
+// withDefaults fills unset fields from the environment
+func (e endpoint) withDefaults() endpoint {
+	if e.client == nil {
+		e.client = defaultHTTPClient()
+	}
+	if e.apiKey == "" {
+		e.apiKey = os.Getenv("OPENAI_API_KEY")
+	}
+	if e.baseURL == "" {
+		e.baseURL = os.Getenv("OPENAI_BASE_URL")
+	}
+	if e.baseURL == "" {
+		e.baseURL = DefaultBaseURL
+	}
+	e.baseURL = strings.TrimSuffix(e.baseURL, "/")
+	return e
+}
+
+// endpoint returns the API endpoint configured by the options
+func (c *config) endpoint() endpoint {
+	e := endpoint{client: c.httpClient, apiKey: c.apiKey, baseURL: c.baseURL}
+	if e.client == nil && c.timeouts != nil {
+		e.client = c.timeouts.httpClient()
+	}
+	return e.withDefaults()
+}
+
+// environmentOr returns the environment set by WithEnvironment, or fallback
+func (c *config) environmentOr(fallback string) string {
+	if c.environment != "" {
+		return c.environment
+	}
+	return fallback
+}
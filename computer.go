@@ -0,0 +1,57 @@
+package computeruse
+
+import (
+	"context"
+	"time"
+)
+
+// Computer is an environment the model operates through the computer tool
+type Computer interface {
+	// Environment returns the computer tool environment: "browser", "mac", "windows" or "ubuntu"
+	Environment() string
+	// Dimensions returns the display size declared to the model
+	Dimensions() (width, height int)
+	Screenshot(ctx context.Context) ([]byte, error)
+	Click(ctx context.Context, x, y int, button string) error
+	DoubleClick(ctx context.Context, x, y int) error
+	Scroll(ctx context.Context, x, y, scrollX, scrollY int) error
+	Type(ctx context.Context, text string) error
+	Keypress(ctx context.Context, keys []string) error
+	Move(ctx context.Context, x, y int) error
+	Wait(ctx context.Context, ms int) error
+}
+
+// urlReporter is implemented by computers that know the URL they are showing
+type urlReporter interface {
+	GetCurrentUrl() string
+}
+
+// dragger is implemented by computers that can drag the mouse along a path
+type dragger interface {
+	Drag(ctx context.Context, path []Point) error
+}
+
+// navigator is implemented by computers that can load a URL, such as Browser
+type navigator interface {
+	Navigate(ctx context.Context, url string) error
+}
+
+// resizer is implemented by computers whose display can be resized, such as Browser
+type resizer interface {
+	Resize(ctx context.Context, width, height int) error
+}
+
+// tabSwitcher is implemented by computers with several tabs, such as Browser
+type tabSwitcher interface {
+	SwitchTab(ctx context.Context, index int) error
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,134 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Credential is a login scoped to a domain and its subdomains
+type Credential struct {
+	Domain   string
+	Username string
+	Password string
+}
+
+// Cookie is a cookie scoped to a domain and its subdomains
+type Cookie struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Domain   string `json:"domain"`
+	Path     string `json:"path,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HTTPOnly bool   `json:"http_only,omitempty"`
+	// Expires is the expiry as Unix time in seconds; zero makes a session cookie
+	Expires float64 `json:"expires,omitempty"`
+}
+
+// domainMatches reports whether host is domain or one of its subdomains
+func domainMatches(host, domain string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	domain = strings.ToLower(strings.TrimPrefix(strings.TrimSuffix(domain, "."), "."))
+	return domain != "" && (host == domain || strings.HasSuffix(host, "."+domain))
+}
+
+// validScope rejects empty and single-label domains such as "com", which would
+// expose a secret to every site under a top-level domain
+func validScope(domain string) error {
+	d := strings.Trim(domain, ".")
+	if d == "" {
+		return fmt.Errorf("a domain is required to scope secrets")
+	}
+	if !strings.Contains(d, ".") && d != "localhost" {
+		return fmt.Errorf("domain %q is too broad to scope secrets", domain)
+	}
+	return nil
+}
+
+// SetCookies adds cookies to the browser. Every cookie must name the domain it
+// is scoped to, so it is never sent to other sites.
+func (b *Browser) SetCookies(ctx context.Context, cookies []Cookie) error {
+	params := make([]*proto.NetworkCookieParam, 0, len(cookies))
+	for _, c := range cookies {
+		if err := validScope(c.Domain); err != nil {
+			return fmt.Errorf("cookie %s: %w", c.Name, err)
+		}
+		path := c.Path
+		if path == "" {
+			path = "/"
+		}
+		params = append(params, &proto.NetworkCookieParam{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+		})
+		if c.Expires > 0 {
+			params[len(params)-1].Expires = proto.TimeSinceEpoch(c.Expires)
+		}
+	}
+	if err := b.browser.Context(ctx).SetCookies(params); err != nil {
+		return fmt.Errorf("error setting cookies: %w", err)
+	}
+	return nil
+}
+
+// credentialTool types a stored credential into the focused field. The secret
+// never reaches the model, and it is only released when the current page
+// belongs to the credential's domain, so a redirect to another site gets nothing.
+func credentialTool(creds []Credential) functionTool {
+	return functionTool{
+		tool: Tool{
+			Type: "function",
+			Name: "fill_credential",
+			Description: "Type the stored username or password for the current site into the focused input field. " +
+				"Click the field first. The value is not revealed to you.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"field": map[string]any{"type": "string", "enum": []string{"username", "password"}},
+				},
+				"required": []string{"field"},
+			},
+		},
+		call: func(ctx context.Context, c Computer, arguments string) (string, error) {
+			var args struct {
+				Field string `json:"field"`
+			}
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return "", fmt.Errorf("invalid fill_credential arguments: %w", err)
+			}
+			b, ok := c.(*Browser)
+			if !ok {
+				return "", fmt.Errorf("fill_credential is only available in the browser environment")
+			}
+			u, err := url.Parse(b.GetCurrentUrl())
+			if err != nil {
+				return "", fmt.Errorf("cannot determine the current site")
+			}
+			if u.Scheme != "https" && u.Hostname() != "localhost" {
+				return "", fmt.Errorf("credentials are only filled on https pages")
+			}
+			for _, cred := range creds {
+				if !domainMatches(u.Hostname(), cred.Domain) {
+					continue
+				}
+				value := cred.Username
+				if args.Field == "password" {
+					value = cred.Password
+				}
+				if err := b.Type(ctx, value); err != nil {
+					return "", err
+				}
+				return fmt.Sprintf("filled %s for %s", args.Field, cred.Domain), nil
+			}
+			return "", fmt.Errorf("no credential is configured for %s", u.Hostname())
+		},
+	}
+}
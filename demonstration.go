@@ -0,0 +1,44 @@
+package computeruse
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Demonstration is a recorded example of how a task was completed
+type Demonstration struct {
+	Instruction string   `json:"instruction"`
+	Steps       []string `json:"steps"`
+	Answer      string   `json:"answer,omitempty"`
+}
+
+// LoadDemonstrations reads a JSON array of demonstrations from a file
+func LoadDemonstrations(path string) ([]Demonstration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading demonstrations: %w", err)
+	}
+	var demos []Demonstration
+	if err := json.Unmarshal(data, &demos); err != nil {
+		return nil, fmt.Errorf("error parsing demonstrations: %w", err)
+	}
+	return demos, nil
+}
+
+// demonstrationMessage formats demonstrations as a context message sent before the task
+func demonstrationMessage(demos []Demonstration) string {
+	var sb strings.Builder
+	sb.WriteString("Here are examples of how similar tasks were completed successfully. Follow the same approach where it applies.\n")
+	for i, d := range demos {
+		fmt.Fprintf(&sb, "\nExample %d\nTask: %s\nSteps:\n", i+1, d.Instruction)
+		for j, step := range d.Steps {
+			fmt.Fprintf(&sb, "%d. %s\n", j+1, step)
+		}
+		if d.Answer != "" {
+			fmt.Fprintf(&sb, "Answer: %s\n", d.Answer)
+		}
+	}
+	return sb.String()
+}
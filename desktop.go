@@ -0,0 +1,121 @@
+package computeruse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/png"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// desktopDriver performs native screen capture and input injection for one OS
+type desktopDriver interface {
+	environment() string
+	screenshot(ctx context.Context) ([]byte, error)
+	move(ctx context.Context, x, y int) error
+	click(ctx context.Context, x, y int, button string, count int) error
+	scroll(ctx context.Context, x, y, scrollX, scrollY int) error
+	typeText(ctx context.Context, text string) error
+	pressKeys(ctx context.Context, keys []string) error
+}
+
+// Desktop drives a desktop, either the native one of the local machine
+// (macOS or Windows), an X11 display or a VNC server, so the agent loop can
+// operate desktop applications and not just a browser
+type Desktop struct {
+	driver desktopDriver
+	width  int
+	height int
+}
+
+// NewDesktop returns a Desktop for the current OS, sized to the primary screen
+func NewDesktop(ctx context.Context) (*Desktop, error) {
+	driver, err := newDesktopDriver()
+	if err != nil {
+		return nil, err
+	}
+	return newDesktop(ctx, driver)
+}
+
+// newDesktop wraps a driver, sized to the screen it captures
+func newDesktop(ctx context.Context, driver desktopDriver) (*Desktop, error) {
+	screenshot, err := driver.screenshot(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error capturing screen: %w", err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(screenshot))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding screenshot: %w", err)
+	}
+	return &Desktop{driver: driver, width: cfg.Width, height: cfg.Height}, nil
+}
+
+// Close releases the connection held by the desktop backend, if any
+func (d *Desktop) Close() error {
+	if c, ok := d.driver.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Environment returns the computer tool environment of the desktop
+func (d *Desktop) Environment() string {
+	return d.driver.environment()
+}
+
+// Dimensions returns the screen size of the desktop
+func (d *Desktop) Dimensions() (int, int) {
+	return d.width, d.height
+}
+
+// Screenshot captures the primary screen
+func (d *Desktop) Screenshot(ctx context.Context) ([]byte, error) {
+	return d.driver.screenshot(ctx)
+}
+
+// Click clicks at the specified coordinates with the specified button
+func (d *Desktop) Click(ctx context.Context, x, y int, button string) error {
+	return d.driver.click(ctx, x, y, button, 1)
+}
+
+// DoubleClick double-clicks at the specified coordinates
+func (d *Desktop) DoubleClick(ctx context.Context, x, y int) error {
+	return d.driver.click(ctx, x, y, "left", 2)
+}
+
+// Scroll scrolls at the specified coordinates
+func (d *Desktop) Scroll(ctx context.Context, x, y, scrollX, scrollY int) error {
+	return d.driver.scroll(ctx, x, y, scrollX, scrollY)
+}
+
+// Type types text into the focused application
+func (d *Desktop) Type(ctx context.Context, text string) error {
+	return d.driver.typeText(ctx, text)
+}
+
+// Keypress presses the keys together as a chord, e.g. ["ctrl", "c"] or ["ctrl+c"]
+func (d *Desktop) Keypress(ctx context.Context, keys []string) error {
+	return d.driver.pressKeys(ctx, splitChords(keys))
+}
+
+// Move moves the mouse to the specified coordinates
+func (d *Desktop) Move(ctx context.Context, x, y int) error {
+	return d.driver.move(ctx, x, y)
+}
+
+// Wait waits for the specified number of milliseconds
+func (d *Desktop) Wait(ctx context.Context, ms int) error {
+	return sleepContext(ctx, time.Duration(ms)*time.Millisecond)
+}
+
+// runScript runs an OS scripting tool and includes its output in errors
+func runScript(ctx context.Context, name string, args ...string) error {
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s failed: %w: %s", name, err, bytes.TrimSpace(out))
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// macDriver drives macOS through screencapture and JavaScript for Automation (osascript)
+type macDriver struct {
+	// pointWidth is the logical screen width; screenshots are scaled to it so
+	// screenshot pixels and event coordinates match on Retina displays
+	pointWidth int
+}
+
+func newDesktopDriver() (desktopDriver, error) {
+	out, err := exec.Command("osascript", "-l", "JavaScript", "-e",
+		`ObjC.import("AppKit"); $.NSScreen.mainScreen.frame.size.width`).Output()
+	if err != nil {
+		return nil, fmt.Errorf("error reading screen size: %w", err)
+	}
+	width, err := strconv.ParseFloat(strings.TrimSpace(string(out)), 64)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing screen size: %w", err)
+	}
+	return &macDriver{pointWidth: int(width)}, nil
+}
+
+func (m *macDriver) environment() string {
+	return "mac"
+}
+
+func (m *macDriver) screenshot(ctx context.Context) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "computeruse")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "screen.png")
+	if err := runScript(ctx, "screencapture", "-x", "-t", "png", file); err != nil {
+		return nil, err
+	}
+	if err := runScript(ctx, "sips", "--resampleWidth", strconv.Itoa(m.pointWidth), file); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(file)
+}
+
+// macMouseJS posts CoreGraphics mouse events
+const macMouseJS = `ObjC.import("CoreGraphics");
+function post(type, x, y, button, count) {
+	const e = $.CGEventCreateMouseEvent(null, type, $.CGPointMake(x, y), button);
+	$.CGEventSetIntegerValueField(e, $.kCGMouseEventClickState, count);
+	$.CGEventPost($.kCGHIDEventTap, e);
+}
+`
+
+func (m *macDriver) jxa(ctx context.Context, script string) error {
+	return runScript(ctx, "osascript", "-l", "JavaScript", "-e", script)
+}
+
+func (m *macDriver) move(ctx context.Context, x, y int) error {
+	return m.jxa(ctx, macMouseJS+fmt.Sprintf("post($.kCGEventMouseMoved, %d, %d, 0, 0);", x, y))
+}
+
+func (m *macDriver) click(ctx context.Context, x, y int, button string, count int) error {
+	down, up, btn := "$.kCGEventLeftMouseDown", "$.kCGEventLeftMouseUp", "$.kCGMouseButtonLeft"
+	if button == "right" {
+		down, up, btn = "$.kCGEventRightMouseDown", "$.kCGEventRightMouseUp", "$.kCGMouseButtonRight"
+	}
+	var sb strings.Builder
+	sb.WriteString(macMouseJS)
+	for i := 1; i <= count; i++ {
+		fmt.Fprintf(&sb, "post(%s, %d, %d, %s, %d); post(%s, %d, %d, %s, %d);\n", down, x, y, btn, i, up, x, y, btn, i)
+	}
+	return m.jxa(ctx, sb.String())
+}
+
+func (m *macDriver) scroll(ctx context.Context, x, y, scrollX, scrollY int) error {
+	script := macMouseJS + fmt.Sprintf(`post($.kCGEventMouseMoved, %d, %d, 0, 0);
+$.CGEventPost($.kCGHIDEventTap, $.CGEventCreateScrollWheelEvent2(null, $.kCGScrollEventUnitPixel, 2, %d, %d, 0));`,
+		x, y, -scrollY, -scrollX)
+	return m.jxa(ctx, script)
+}
+
+func (m *macDriver) typeText(ctx context.Context, text string) error {
+	arg, _ := json.Marshal(text)
+	return m.jxa(ctx, fmt.Sprintf(`Application("System Events").keystroke(%s);`, arg))
+}
+
+// macKeyCodes maps key names to macOS virtual key codes
+var macKeyCodes = map[string]int{
+	"enter": 36, "return": 36, "tab": 48, "space": 49, "backspace": 51, "escape": 53, "esc": 53,
+	"delete": 117, "home": 115, "end": 119, "page_up": 116, "pageup": 116, "page_down": 121, "pagedown": 121,
+	"left": 123, "arrowleft": 123, "right": 124, "arrowright": 124, "down": 125, "arrowdown": 125, "up": 126, "arrowup": 126,
+}
+
+// macModifiers maps modifier names to System Events modifier flags
+var macModifiers = map[string]string{
+	"cmd": "command down", "command": "command down", "meta": "command down", "super": "command down",
+	"ctrl": "control down", "control": "control down",
+	"alt": "option down", "option": "option down",
+	"shift": "shift down",
+}
+
+func (m *macDriver) pressKeys(ctx context.Context, keys []string) error {
+	var using []string
+	var script strings.Builder
+	for _, key := range keys {
+		name := strings.ToLower(key)
+		if mod, ok := macModifiers[name]; ok {
+			using = append(using, mod)
+			continue
+		}
+		mods, _ := json.Marshal(using)
+		if code, ok := macKeyCodes[name]; ok {
+			fmt.Fprintf(&script, `Application("System Events").keyCode(%d, {using: %s});`, code, mods)
+		} else if len([]rune(key)) == 1 {
+			char, _ := json.Marshal(name)
+			fmt.Fprintf(&script, `Application("System Events").keystroke(%s, {using: %s});`, char, mods)
+		} else {
+			return fmt.Errorf("key: %v is not supported", key)
+		}
+	}
+	if script.Len() == 0 {
+		return nil
+	}
+	return m.jxa(ctx, script.String())
+}
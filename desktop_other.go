@@ -0,0 +1,12 @@
+//go:build !darwin && !windows
+
+package computeruse
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func newDesktopDriver() (desktopDriver, error) {
+	return nil, fmt.Errorf("native desktop is not supported on %s", runtime.GOOS)
+}
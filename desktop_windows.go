@@ -0,0 +1,151 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// windowsDriver drives Windows through PowerShell, System.Windows.Forms and user32.dll
+type windowsDriver struct{}
+
+func newDesktopDriver() (desktopDriver, error) {
+	return &windowsDriver{}, nil
+}
+
+func (w *windowsDriver) environment() string {
+	return "windows"
+}
+
+// windowsPrelude loads the assemblies and user32 functions used by the scripts
+const windowsPrelude = `Add-Type -AssemblyName System.Windows.Forms,System.Drawing
+Add-Type @"
+using System.Runtime.InteropServices;
+public static class Input {
+	[DllImport("user32.dll")] public static extern bool SetCursorPos(int x, int y);
+	[DllImport("user32.dll")] public static extern void mouse_event(uint flags, uint dx, uint dy, int data, System.UIntPtr extra);
+	[DllImport("user32.dll")] public static extern bool SetProcessDPIAware();
+	[DllImport("user32.dll")] public static extern void keybd_event(byte vk, byte scan, uint flags, System.UIntPtr extra);
+}
+"@
+[Input]::SetProcessDPIAware() | Out-Null
+`
+
+func (w *windowsDriver) powershell(ctx context.Context, script string) error {
+	return runScript(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsPrelude+script)
+}
+
+func (w *windowsDriver) screenshot(ctx context.Context) ([]byte, error) {
+	dir, err := os.MkdirTemp("", "computeruse")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "screen.png")
+	script := fmt.Sprintf(`$b = [System.Windows.Forms.Screen]::PrimaryScreen.Bounds
+$bmp = New-Object System.Drawing.Bitmap $b.Width, $b.Height
+$g = [System.Drawing.Graphics]::FromImage($bmp)
+$g.CopyFromScreen($b.Location, [System.Drawing.Point]::Empty, $b.Size)
+$bmp.Save('%s', [System.Drawing.Imaging.ImageFormat]::Png)`, strings.ReplaceAll(file, "'", "''"))
+	if err := w.powershell(ctx, script); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(file)
+}
+
+func (w *windowsDriver) move(ctx context.Context, x, y int) error {
+	return w.powershell(ctx, fmt.Sprintf("[Input]::SetCursorPos(%d, %d) | Out-Null", x, y))
+}
+
+func (w *windowsDriver) click(ctx context.Context, x, y int, button string, count int) error {
+	down, up := 0x0002, 0x0004 // MOUSEEVENTF_LEFTDOWN, MOUSEEVENTF_LEFTUP
+	if button == "right" {
+		down, up = 0x0008, 0x0010
+	}
+	script := fmt.Sprintf("[Input]::SetCursorPos(%d, %d) | Out-Null\n", x, y)
+	for i := 0; i < count; i++ {
+		script += fmt.Sprintf("[Input]::mouse_event(%d, 0, 0, 0, [System.UIntPtr]::Zero)\n[Input]::mouse_event(%d, 0, 0, 0, [System.UIntPtr]::Zero)\n", down, up)
+	}
+	return w.powershell(ctx, script)
+}
+
+func (w *windowsDriver) scroll(ctx context.Context, x, y, scrollX, scrollY int) error {
+	script := fmt.Sprintf("[Input]::SetCursorPos(%d, %d) | Out-Null\n", x, y)
+	if scrollY != 0 {
+		script += fmt.Sprintf("[Input]::mouse_event(0x0800, 0, 0, %d, [System.UIntPtr]::Zero)\n", -scrollY) // MOUSEEVENTF_WHEEL
+	}
+	if scrollX != 0 {
+		script += fmt.Sprintf("[Input]::mouse_event(0x1000, 0, 0, %d, [System.UIntPtr]::Zero)\n", scrollX) // MOUSEEVENTF_HWHEEL
+	}
+	return w.powershell(ctx, script)
+}
+
+// sendKeysEscaper escapes characters with a special meaning to SendKeys
+var sendKeysEscaper = strings.NewReplacer(
+	"+", "{+}", "^", "{^}", "%", "{%}", "~", "{~}",
+	"(", "{(}", ")", "{)}", "{", "{{}", "}", "{}}", "[", "{[}", "]", "{]}",
+	"'", "''",
+)
+
+func (w *windowsDriver) typeText(ctx context.Context, text string) error {
+	return w.powershell(ctx, fmt.Sprintf("[System.Windows.Forms.SendKeys]::SendWait('%s')", sendKeysEscaper.Replace(text)))
+}
+
+// windowsKeys maps key names to SendKeys codes
+var windowsKeys = map[string]string{
+	"enter": "{ENTER}", "return": "{ENTER}", "tab": "{TAB}", "space": " ", "backspace": "{BACKSPACE}",
+	"escape": "{ESC}", "esc": "{ESC}", "delete": "{DELETE}", "home": "{HOME}", "end": "{END}",
+	"page_up": "{PGUP}", "pageup": "{PGUP}", "page_down": "{PGDN}", "pagedown": "{PGDN}",
+	"left": "{LEFT}", "arrowleft": "{LEFT}", "right": "{RIGHT}", "arrowright": "{RIGHT}",
+	"up": "{UP}", "arrowup": "{UP}", "down": "{DOWN}", "arrowdown": "{DOWN}",
+}
+
+// windowsKeyNames are the names of the Windows key, which SendKeys cannot
+// send and is held with keybd_event instead
+var windowsKeyNames = map[string]bool{"meta": true, "win": true, "windows": true, "super": true}
+
+// windowsModifiers maps modifier names to SendKeys prefixes
+var windowsModifiers = map[string]string{
+	"ctrl": "^", "control": "^", "cmd": "^", "command": "^",
+	"alt": "%", "option": "%",
+	"shift": "+",
+}
+
+func (w *windowsDriver) pressKeys(ctx context.Context, keys []string) error {
+	var mods, seq string
+	var win bool
+	for _, key := range keys {
+		name := strings.ToLower(key)
+		if windowsKeyNames[name] {
+			win = true
+			continue
+		}
+		if mod, ok := windowsModifiers[name]; ok {
+			mods += mod
+			continue
+		}
+		if code, ok := windowsKeys[name]; ok {
+			seq += mods + code
+		} else if len([]rune(key)) == 1 {
+			seq += mods + sendKeysEscaper.Replace(name)
+		} else {
+			return fmt.Errorf("key: %v is not supported", key)
+		}
+	}
+	script := ""
+	if seq != "" {
+		script = fmt.Sprintf("[System.Windows.Forms.SendKeys]::SendWait('%s')\n", seq)
+	}
+	if win {
+		// VK_LWIN down, the keys, then KEYEVENTF_KEYUP
+		script = "[Input]::keybd_event(0x5B, 0, 0, [System.UIntPtr]::Zero)\n" + script +
+			"[Input]::keybd_event(0x5B, 0, 2, [System.UIntPtr]::Zero)\n"
+	}
+	if script == "" {
+		return nil
+	}
+	return w.powershell(ctx, script)
+}
@@ -0,0 +1,192 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Download is a file downloaded by the browser during a run
+type Download struct {
+	GUID     string `json:"guid"`
+	URL      string `json:"url"`
+	Filename string `json:"filename"`
+	Path     string `json:"path"`
+	State    string `json:"state"`
+}
+
+// DownloadParser extracts data from a downloaded file
+type DownloadParser func(d Download) (any, error)
+
+// DownloadResult is a completed download with the data extracted by its parser
+type DownloadResult struct {
+	Download
+	Data  any    `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// downloadTracker follows download events of a browser
+type downloadTracker struct {
+	mu        sync.Mutex
+	dir       string
+	downloads map[string]*Download
+	order     []string
+	// completed lists downloads finished since the last call to finished
+	completed []Download
+	onDone    func(Download)
+}
+
+// EnableDownloads saves files downloaded by pages into dir and tracks them
+func (b *Browser) EnableDownloads(ctx context.Context, dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("error resolving download directory: %w", err)
+	}
+	if err := os.MkdirAll(abs, 0755); err != nil {
+		return fmt.Errorf("error creating download directory: %w", err)
+	}
+	err = proto.BrowserSetDownloadBehavior{
+		Behavior:         proto.BrowserSetDownloadBehaviorBehaviorAllowAndName,
+		BrowserContextID: b.browser.BrowserContextID,
+		DownloadPath:     abs,
+		EventsEnabled:    true,
+	}.Call(b.browser.Context(ctx))
+	if err != nil {
+		return fmt.Errorf("error enabling downloads: %w", err)
+	}
+
+	t := &downloadTracker{dir: abs, downloads: map[string]*Download{}}
+	b.downloads = t
+	go b.browser.EachEvent(func(e *proto.BrowserDownloadWillBegin) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.downloads[e.GUID] = &Download{
+			GUID:     e.GUID,
+			URL:      e.URL,
+			Filename: e.SuggestedFilename,
+			Path:     filepath.Join(abs, e.GUID),
+			State:    string(proto.BrowserDownloadProgressStateInProgress),
+		}
+		t.order = append(t.order, e.GUID)
+	}, func(e *proto.BrowserDownloadProgress) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		d, ok := t.downloads[e.GUID]
+		if !ok || d.State == string(e.State) {
+			return
+		}
+		d.State = string(e.State)
+		if e.State == proto.BrowserDownloadProgressStateCompleted {
+			t.completed = append(t.completed, *d)
+			if t.onDone != nil {
+				go t.onDone(*d)
+			}
+		}
+	})()
+	return nil
+}
+
+// Downloads waits briefly for in-progress downloads to finish and returns every
+// download seen so far. Completed files are renamed to their suggested filename.
+func (b *Browser) Downloads(ctx context.Context) []Download {
+	t := b.downloads
+	if t == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	for t.pending() > 0 {
+		if sleepContext(ctx, 200*time.Millisecond) != nil {
+			break
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var downloads []Download
+	for _, guid := range t.order {
+		d := t.downloads[guid]
+		if d.State == string(proto.BrowserDownloadProgressStateCompleted) && filepath.Base(d.Path) == d.GUID && d.Filename != "" {
+			named := filepath.Join(t.dir, d.GUID[:8]+"-"+filepath.Base(d.Filename))
+			if err := os.Rename(d.Path, named); err == nil {
+				d.Path = named
+			}
+		}
+		downloads = append(downloads, *d)
+	}
+	return downloads
+}
+
+// OnDownload calls fn with every download that completes. The file is still
+// named by its GUID until Downloads renames it.
+func (b *Browser) OnDownload(fn func(Download)) {
+	if t := b.downloads; t != nil {
+		t.mu.Lock()
+		t.onDone = fn
+		t.mu.Unlock()
+	}
+}
+
+// finishedDownloads returns the downloads completed since the previous call
+func (b *Browser) finishedDownloads() []Download {
+	t := b.downloads
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	done := t.completed
+	t.completed = nil
+	return done
+}
+
+func (t *downloadTracker) pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for _, d := range t.downloads {
+		if d.State == string(proto.BrowserDownloadProgressStateInProgress) {
+			n++
+		}
+	}
+	return n
+}
+
+// parseDownloads runs the parser registered for each completed download's extension
+func parseDownloads(downloads []Download, parsers map[string]DownloadParser) []DownloadResult {
+	var results []DownloadResult
+	for _, d := range downloads {
+		r := DownloadResult{Download: d}
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(d.Filename), "."))
+		if parse, ok := parsers[ext]; ok && d.State == string(proto.BrowserDownloadProgressStateCompleted) {
+			data, err := parse(d)
+			if err != nil {
+				r.Error = err.Error()
+			}
+			r.Data = data
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// ParseCSV is a DownloadParser returning the rows of a CSV file
+func ParseCSV(d Download) (any, error) {
+	f, err := os.Open(d.Path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", d.Filename, err)
+	}
+	defer f.Close()
+	rows, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", d.Filename, err)
+	}
+	return rows, nil
+}
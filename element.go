@@ -0,0 +1,85 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-rod/rod"
+)
+
+// ElementInfo describes the DOM element at a point of the page
+type ElementInfo struct {
+	Tag  string `json:"tag"`
+	Role string `json:"role,omitempty"`
+	// Name approximates the accessible name: aria-label, labelledby text, alt, title, text or placeholder
+	Name   string `json:"name,omitempty"`
+	X      int    `json:"x"`
+	Y      int    `json:"y"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+	// Type is the type attribute of inputs and buttons, e.g. "password"
+	Type string `json:"type,omitempty"`
+	// Submit reports whether the element submits a form
+	Submit bool `json:"submit,omitempty"`
+}
+
+// describeElementJS defines describe(el), which returns an ElementInfo of el
+const describeElementJS = `const describe = (el) => {
+	const clip = (s) => (s || "").replace(/\s+/g, " ").trim().slice(0, 80);
+	const labelled = (el.getAttribute("aria-labelledby") || "").split(" ")
+		.map((id) => document.getElementById(id)).filter(Boolean).map((e) => e.innerText).join(" ");
+	const name = el.getAttribute("aria-label") || labelled || el.getAttribute("alt") ||
+		el.getAttribute("title") || el.innerText || el.value || el.getAttribute("placeholder");
+	const r = el.getBoundingClientRect();
+	const button = el.closest("button, input[type=submit], input[type=image]");
+	return {
+		tag: el.tagName.toLowerCase(), role: el.getAttribute("role") || "", name: clip(name),
+		x: Math.round(r.left), y: Math.round(r.top), width: Math.round(r.width), height: Math.round(r.height),
+		type: (el.getAttribute("type") || "").toLowerCase(),
+		submit: !!button && !!button.form && (button.type === "submit" || button.type === "image"),
+	};
+};`
+
+// elementAtJS describes the element at a viewport point, or returns null
+const elementAtJS = `(x, y) => {
+	` + describeElementJS + `
+	const el = document.elementFromPoint(x, y);
+	return el ? describe(el) : null;
+}`
+
+// focusedElementJS describes the element with keyboard focus, or returns null
+const focusedElementJS = `() => {
+	` + describeElementJS + `
+	const el = document.activeElement;
+	return el && el !== document.body ? describe(el) : null;
+}`
+
+// ElementAt returns the element at viewport coordinates (x, y), or nil when there is none
+func (b *Browser) ElementAt(ctx context.Context, x, y int) (*ElementInfo, error) {
+	return b.describeElement(ctx, elementAtJS, x, y)
+}
+
+// FocusedElement returns the element with keyboard focus, or nil when there is none
+func (b *Browser) FocusedElement(ctx context.Context) (*ElementInfo, error) {
+	return b.describeElement(ctx, focusedElementJS)
+}
+
+// describeElement evaluates js, which returns an ElementInfo or null
+func (b *Browser) describeElement(ctx context.Context, js string, args ...any) (*ElementInfo, error) {
+	var info *ElementInfo
+	err := b.do(ctx, func(page *rod.Page) error {
+		obj, err := page.Eval(js, args...)
+		if err != nil {
+			return err
+		}
+		if obj.Value.Nil() {
+			return nil
+		}
+		info = &ElementInfo{}
+		return obj.Value.Unmarshal(info)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error locating element: %w", err)
+	}
+	return info, nil
+}
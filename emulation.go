@@ -0,0 +1,135 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// emulation is the identity a browser presents to sites: user agent, locale,
+// timezone and the localStorage entries preloaded per origin
+type emulation struct {
+	userAgent string
+	locale    string
+	timezone  string
+	// localStorage maps origins such as "https://example.com" to their entries
+	localStorage map[string]map[string]string
+}
+
+// preloadStorageJS fills the localStorage of the origins in preload before
+// any script of the page runs, without overwriting entries the site set itself
+const preloadStorageJS = `((preload) => {
+	const items = preload[location.origin];
+	if (!items) return;
+	try {
+		for (const [k, v] of Object.entries(items)) {
+			if (localStorage.getItem(k) === null) localStorage.setItem(k, v);
+		}
+	} catch (e) {}
+})(%s)`
+
+// apply sets the emulation on a page before it loads its first document
+func (e emulation) apply(page *rod.Page) error {
+	if e.userAgent != "" || e.locale != "" {
+		ua := e.userAgent
+		if ua == "" {
+			version, err := proto.BrowserGetVersion{}.Call(page)
+			if err != nil {
+				return fmt.Errorf("error reading user agent: %w", err)
+			}
+			ua = version.UserAgent
+		}
+		err := proto.NetworkSetUserAgentOverride{UserAgent: ua, AcceptLanguage: e.locale}.Call(page)
+		if err != nil {
+			return fmt.Errorf("error setting user agent: %w", err)
+		}
+	}
+	if e.locale != "" {
+		if err := (proto.EmulationSetLocaleOverride{Locale: e.locale}).Call(page); err != nil {
+			return fmt.Errorf("error setting locale %s: %w", e.locale, err)
+		}
+	}
+	if e.timezone != "" {
+		if err := (proto.EmulationSetTimezoneOverride{TimezoneID: e.timezone}).Call(page); err != nil {
+			return fmt.Errorf("error setting timezone %s: %w", e.timezone, err)
+		}
+	}
+	if len(e.localStorage) > 0 {
+		preload, err := json.Marshal(e.localStorage)
+		if err != nil {
+			return fmt.Errorf("error encoding localStorage: %w", err)
+		}
+		_, err = proto.PageAddScriptToEvaluateOnNewDocument{Source: fmt.Sprintf(preloadStorageJS, preload)}.Call(page)
+		if err != nil {
+			return fmt.Errorf("error preloading localStorage: %w", err)
+		}
+	}
+	return nil
+}
+
+// Cookies returns the cookies of the browser, e.g. to start a later session
+// already logged in with WithCookies
+func (b *Browser) Cookies(ctx context.Context) ([]Cookie, error) {
+	cookies, err := b.browser.Context(ctx).GetCookies()
+	if err != nil {
+		return nil, fmt.Errorf("error reading cookies: %w", err)
+	}
+	exported := make([]Cookie, 0, len(cookies))
+	for _, c := range cookies {
+		exported = append(exported, Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   c.Domain,
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HTTPOnly: c.HTTPOnly,
+			Expires:  float64(c.Expires),
+		})
+	}
+	return exported, nil
+}
+
+// SaveCookies writes cookies as JSON. The file holds session cookies, so it is private to the user.
+func SaveCookies(path string, cookies []Cookie) error {
+	data, err := json.MarshalIndent(cookies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding cookies: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error saving cookies: %w", err)
+	}
+	return nil
+}
+
+// LoadCookies reads cookies saved by SaveCookies or WithCookieExport
+func LoadCookies(path string) ([]Cookie, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cookies: %w", err)
+	}
+	var cookies []Cookie
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return nil, fmt.Errorf("error decoding cookies: %w", err)
+	}
+	return cookies, nil
+}
+
+// exportCookies saves the browser's cookies at the end of the run for WithCookieExport
+func exportCookies(ctx context.Context, browser *Browser, cfg *config, result *Result) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.actionTimeout)
+	defer cancel()
+	cookies, err := browser.Cookies(ctx)
+	if err == nil {
+		err = SaveCookies(cfg.cookieExportPath, cookies)
+	}
+	if err != nil {
+		cfg.events.error(err)
+		return
+	}
+	result.CookieFile = cfg.cookieExportPath
+	cfg.events.notice(fmt.Sprintf("🍪 %d cookies exported: %s", len(cookies), cfg.cookieExportPath))
+}
@@ -0,0 +1,107 @@
+package computeruse
+
+import "fmt"
+
+// Events receives notifications about the progress of a run.
+// Nil callbacks are skipped.
+type Events struct {
+	// OnTurnStart is called before the input of a turn is sent to the model
+	OnTurnStart func(turn int, input []Input)
+	// OnResponse is called with every response of the model
+	OnResponse func(turn int, response *Response)
+	// OnAction is called before a computer action is executed
+	OnAction func(turn int, action Action)
+	// OnScreenshot is called when the screenshot after an action has been saved to file
+	OnScreenshot func(turn int, file string, out *ComputerOutput)
+	// OnAssistantMessage is called with the text of every assistant message
+	OnAssistantMessage func(turn int, text string)
+	// OnSafetyCheck is called for every safety check with its resolution
+	OnSafetyCheck func(record SafetyCheckRecord)
+	// OnDownload is called when a browser download started during the run completes
+	OnDownload func(d Download)
+	// OnError is called for errors that do not stop the run, such as a screenshot that could not be saved
+	OnError func(err error)
+	// OnNotice is called with status messages, such as retries and the final output
+	OnNotice func(message string)
+}
+
+// ConsoleEvents returns the default handler, which prints the progress of the run to stdout
+func ConsoleEvents() *Events {
+	return &Events{
+		OnTurnStart: func(turn int, input []Input) {
+			debugInput(input)
+		},
+		OnResponse: func(turn int, response *Response) {
+			debugResponse(response)
+		},
+		OnScreenshot: func(turn int, file string, out *ComputerOutput) {
+			debugScreenshot(file, out)
+		},
+		OnSafetyCheck: func(record SafetyCheckRecord) {
+			fmt.Printf("🛡️ Safety check %s: %s\n", record.Code, record.Resolution)
+		},
+		OnDownload: func(d Download) {
+			fmt.Printf("📥 Downloaded %s\n", d.Filename)
+		},
+		OnError: func(err error) {
+			fmt.Printf("❌ %v\n", err)
+		},
+		OnNotice: func(message string) {
+			fmt.Println(message)
+		},
+	}
+}
+
+func (e *Events) turnStart(turn int, input []Input) {
+	if e != nil && e.OnTurnStart != nil {
+		e.OnTurnStart(turn, input)
+	}
+}
+
+func (e *Events) response(turn int, response *Response) {
+	if e != nil && e.OnResponse != nil {
+		e.OnResponse(turn, response)
+	}
+}
+
+func (e *Events) action(turn int, action Action) {
+	if e != nil && e.OnAction != nil {
+		e.OnAction(turn, action)
+	}
+}
+
+func (e *Events) screenshot(turn int, file string, out *ComputerOutput) {
+	if e != nil && e.OnScreenshot != nil {
+		e.OnScreenshot(turn, file, out)
+	}
+}
+
+func (e *Events) assistantMessage(turn int, text string) {
+	if e != nil && e.OnAssistantMessage != nil {
+		e.OnAssistantMessage(turn, text)
+	}
+}
+
+func (e *Events) safetyCheck(record SafetyCheckRecord) {
+	if e != nil && e.OnSafetyCheck != nil {
+		e.OnSafetyCheck(record)
+	}
+}
+
+func (e *Events) download(d Download) {
+	if e != nil && e.OnDownload != nil {
+		e.OnDownload(d)
+	}
+}
+
+func (e *Events) error(err error) {
+	if e != nil && e.OnError != nil {
+		e.OnError(err)
+	}
+}
+
+func (e *Events) notice(message string) {
+	if e != nil && e.OnNotice != nil {
+		e.OnNotice(message)
+	}
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	cu "github.com/masacento/openai-computeruse-example"
@@ -19,6 +21,98 @@ func main() {
 	prompt := flag.String("prompt", "Find out the winner of the Academy Award for Best Picture in 2025 and tell me the title.", "Instruction to execute")
 	maxturns := flag.Int("maxturns", 16, "Maximum number of turns (optional)")
 	timeout := flag.String("timeout", "3m", "Timeout duration (optional)")
+	model := flag.String("model", cu.DefaultModel, "Computer-use model (optional)")
+	baseURL := flag.String("baseurl", "", "OpenAI-compatible API endpoint, defaults to OPENAI_BASE_URL or the OpenAI API (optional)")
+	modelsFile := flag.String("models", "", "JSON file registering additional models (optional)")
+	desktop := flag.Bool("desktop", false, "Operate the native desktop (macOS/Windows) instead of a browser (optional)")
+	x11 := flag.String("x11", "", "X11 display to operate, e.g. :1 (optional)")
+	vnc := flag.String("vnc", "", "VNC server to operate, e.g. localhost:5900; password from VNC_PASSWORD (optional)")
+	domsnapshots := flag.Bool("domsnapshots", false, "Save an MHTML snapshot next to each screenshot (optional)")
+	uploads := flag.Bool("uploads", false, "Send screenshots through the Files API instead of inline (optional)")
+	delay := flag.Duration("delay", 0, "Delay after each action before the screenshot (optional)")
+	jpegQuality := flag.Int("jpeg", 0, "Send screenshots as JPEG at this quality, 1-100 (optional)")
+	skipUnchanged := flag.Bool("skipunchanged", false, "Send a placeholder instead of screenshots identical to the previous one (optional)")
+	reuse := flag.String("reuseshots", "", "Comma-separated action types that reuse the previous screenshot, e.g. move,wait (optional)")
+	human := flag.Bool("human", false, "Human-like mouse movement and typing cadence (optional)")
+	viewport := flag.Bool("viewport", false, "Tell the model the scroll position after each action (optional)")
+	pagemeta := flag.Bool("pagemeta", false, "Tell the model the page title, HTTP status and load state after each action (optional)")
+	pagetext := flag.Int("pagetext", 0, "Send up to this many characters of page text and accessibility tree with each screenshot (optional)")
+	memory := flag.Bool("memory", false, "Offer the model a key-value memory across pages (optional)")
+	retries := flag.Int("retries", 0, "Retry a failed task with a refined prompt this many times (optional)")
+	resilient := flag.Bool("resilient", false, "Retry transient API errors and save a checkpoint when they persist (optional)")
+	checkpoint := flag.String("checkpoint", "checkpoint.json", "Checkpoint file written in resilient mode (optional)")
+	approve := flag.String("approve", "", "Ask in the terminal before these actions: comma-separated submit, password, all, or enter:<domain> (optional)")
+	maxTokens := flag.Int("maxtokens", 0, "Stop the run after this many tokens (optional)")
+	maxCost := flag.Float64("maxcost", 0, "Stop the run after spending this many US dollars (optional)")
+	traceDir := flag.String("trace", "", "Write a replayable trace of the run under this directory (optional)")
+	replay := flag.String("replay", "", "Replay the actions of a trace directory in a browser without calling the API (optional)")
+	turnTimeout := flag.Duration("turntimeout", 0, "Deadline for each turn, the API call plus its actions (optional)")
+	resize := flag.Bool("resize", false, "Let the model resize the browser viewport (optional)")
+	tabs := flag.Bool("tabs", false, "Let the model switch between browser tabs (optional)")
+	budget := flag.Bool("budget", false, "Tell the model how many turns are left and ask for an answer on the last one (optional)")
+	export := flag.String("export", "", "Append the run to this JSONL fine-tuning dataset if it completes (optional)")
+	sessionFile := flag.String("session", "", "Save the session state after every turn to this file (optional)")
+	resumeSession := flag.Bool("resumesession", false, "Continue the session saved in the -session file (optional)")
+	resume := flag.String("resume", "", "Resume the session saved in a checkpoint file (optional)")
+	ratelimitwait := flag.Duration("ratelimitwait", 0, "Pause up to this long when the rate limit is exhausted, then resume (optional)")
+	autoack := flag.Bool("autoack", false, "Acknowledge safety checks without asking in the terminal (optional)")
+	reasoning := flag.String("reasoning", "", "Record the model's reasoning summary per turn: auto, concise or detailed (optional)")
+	downloads := flag.String("downloads", "", "Directory to save downloaded files into; CSV files are parsed (optional)")
+	attach := flag.String("attach", "", "Comma-separated files the model may upload through file choosers (optional)")
+	scrollhelper := flag.Bool("scrollhelper", false, "Offer the scroll_until helper tool to the model (optional)")
+	language := flag.String("language", "", "Language the final answer must be written in (optional)")
+	steering := flag.String("steering", "", "Note appended to every turn to keep the model on track (optional)")
+	demos := flag.String("demos", "", "JSON file with demonstrations of similar tasks (optional)")
+	savestate := flag.String("savestate", "", "Save the final page state (URL, cookies, storage) to this file (optional)")
+	warmstart := flag.String("warmstart", "", "Start from a page state saved with -savestate (optional)")
+	stream := flag.Bool("stream", false, "Stream responses and print assistant text as it arrives (optional)")
+	display := flag.String("display", "", "Browser viewport size, e.g. 1920x1080; screenshots are downscaled for the model (optional)")
+	cdp := flag.String("cdp", "", "Attach to a running browser: DevTools WebSocket URL or host:port of its debugging port (optional)")
+	headful := flag.Bool("headful", false, "Show the launched browser window (optional)")
+	userdatadir := flag.String("userdatadir", "", "Persistent browser profile directory, e.g. to reuse logged-in sessions (optional)")
+	proxy := flag.String("proxy", "", "Proxy for the launched browser, e.g. http://localhost:3128 or socks5://localhost:1080 (optional)")
+	userAgent := flag.String("useragent", "", "User agent of the browser pages (optional)")
+	locale := flag.String("locale", "", "Locale and Accept-Language of the browser pages, e.g. ja-JP (optional)")
+	timezone := flag.String("timezone", "", "Timezone of the browser pages, e.g. Asia/Tokyo (optional)")
+	cookieFile := flag.String("cookies", "", "Load cookies saved with -exportcookies before the run (optional)")
+	exportCookies := flag.String("exportcookies", "", "Save the browser's cookies to this file when the run ends (optional)")
+	nosandbox := flag.Bool("nosandbox", false, "Disable the Chrome sandbox, needed when running as root in containers (optional)")
+	keepprofile := flag.Bool("keepprofile", false, "Keep the temporary browser profile on disk after the session (optional)")
+	verify := flag.String("verify", "", "Judge model that verifies the final answer against the final screenshot, e.g. gpt-4o-mini (optional)")
+	samples := flag.Int("samples", 1, "Run the task in this many parallel browsers and take the majority answer (optional)")
+	batchFile := flag.String("batch", "", "JSON file with a list of {url, instruction} tasks to run in parallel, used instead of -url and -prompt (optional)")
+	concurrency := flag.Int("concurrency", 4, "Number of -batch tasks running at once (optional)")
+	shared := flag.Bool("shared", false, "Run -batch tasks in incognito contexts of one browser (optional)")
+	taskFile := flag.String("task", "", "JSON task file mixing scripted and prompt steps, used instead of -url and -prompt (optional)")
+	tmplFile := flag.String("template", "", "Prompt template file used instead of -prompt (optional)")
+	var vars []string
+	var privacy []string
+	var navPolicy cu.NavigationPolicy
+	tags := map[string]string{}
+	flag.Func("tag", "Session tag as key=value, may be repeated (optional)", func(v string) error {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return fmt.Errorf("tag must be key=value")
+		}
+		tags[key] = value
+		return nil
+	})
+	flag.Func("privacy", "CSS selector blacked out in screenshots, may be repeated (optional)", func(v string) error {
+		privacy = append(privacy, v)
+		return nil
+	})
+	flag.Func("allow", "Host the agent may visit, e.g. example.com or *.example.com, may be repeated (optional)", func(v string) error {
+		navPolicy.Allow = append(navPolicy.Allow, v)
+		return nil
+	})
+	flag.Func("block", "Host the agent must not visit, may be repeated (optional)", func(v string) error {
+		navPolicy.Block = append(navPolicy.Block, v)
+		return nil
+	})
+	flag.Func("var", "Template variable as key=value, may be repeated (optional)", func(v string) error {
+		vars = append(vars, v)
+		return nil
+	})
 	flag.Parse()
 
 	to, err := time.ParseDuration(*timeout)
@@ -26,15 +120,356 @@ func main() {
 		log.Fatalf("invalid timeout: %v", err)
 	}
 
+	if *tmplFile != "" {
+		tmpl, err := cu.LoadPromptTemplate(*tmplFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		tmplVars, err := cu.ParseTemplateVars(vars)
+		if err != nil {
+			log.Fatal(err)
+		}
+		*prompt, err = tmpl.Render(tmplVars)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), to)
 	defer cancel()
 
 	fmt.Println("Prompt:", *prompt)
 	fmt.Println("URL   :", *url)
 
-	err = cu.BrowserUse(ctx, *url, *prompt, *maxturns)
+	if *modelsFile != "" {
+		if err := cu.LoadModelRegistry(*modelsFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	opts := []cu.Option{cu.WithModel(*model)}
+	if len(tags) > 0 {
+		opts = append(opts, cu.WithTags(tags))
+	}
+	if *baseURL != "" {
+		opts = append(opts, cu.WithBaseURL(*baseURL))
+	}
+	if *scrollhelper {
+		opts = append(opts, cu.WithScrollHelper())
+	}
+	if *domsnapshots {
+		opts = append(opts, cu.WithDOMSnapshots())
+	}
+	if *jpegQuality > 0 || *skipUnchanged {
+		opts = append(opts, cu.WithScreenshotPipeline(cu.ScreenshotPipeline{JPEGQuality: *jpegQuality, SkipUnchanged: *skipUnchanged}))
+	}
+	if *reuse != "" {
+		opts = append(opts, cu.WithScreenshotReuse(strings.Split(*reuse, ",")...))
+	}
+	if *delay > 0 {
+		opts = append(opts, cu.WithActionDelays(map[string]time.Duration{"*": *delay}))
+	}
+	if *human {
+		opts = append(opts, cu.WithHumanPacing())
+	}
+	if *viewport {
+		opts = append(opts, cu.WithViewportInfo())
+	}
+	if *pagemeta {
+		opts = append(opts, cu.WithPageMetadata())
+	}
+	if *pagetext > 0 {
+		opts = append(opts, cu.WithPageContext(cu.PageContext{Text: true, Accessibility: true, MaxChars: *pagetext}))
+	}
+	if *memory {
+		opts = append(opts, cu.WithMemory(nil))
+	}
+	if *retries > 0 {
+		opts = append(opts, cu.WithTaskRetry(*retries, nil))
+	}
+	if *resilient {
+		opts = append(opts, cu.WithResilience(cu.Resilient, 3, *checkpoint))
+	}
+	if *resume != "" {
+		cp, err := cu.LoadCheckpoint(*resume)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if cp.CurrentURL != "" {
+			*url = cp.CurrentURL
+		}
+		*prompt = cp.Instruction
+		opts = append(opts, cu.WithCheckpoint(cp))
+		if len(cp.Memory) > 0 && !*memory {
+			opts = append(opts, cu.WithMemory(nil))
+		}
+	}
+	if *sessionFile != "" && !*resumeSession {
+		opts = append(opts, cu.WithSessionFile(*sessionFile))
+	}
+	if *ratelimitwait > 0 {
+		opts = append(opts, cu.WithRateLimitPause(*ratelimitwait))
+	}
+	if *autoack {
+		opts = append(opts, cu.WithSafetyCheckHandler(cu.AutoAcknowledge))
+	} else {
+		opts = append(opts, cu.WithSafetyCheckHandler(cu.TerminalSafetyCheckHandler(os.Stdin, os.Stdout)))
+	}
+	if *reasoning != "" {
+		opts = append(opts, cu.WithReasoningSummary(*reasoning))
+	}
+	if *savestate != "" {
+		opts = append(opts, cu.WithSaveState(*savestate))
+	}
+	if *warmstart != "" {
+		state, err := cu.LoadPageState(*warmstart)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, cu.WithWarmStart(state))
+	}
+	if *stream {
+		opts = append(opts, cu.WithStreaming(func(e cu.StreamEvent) {
+			if e.Type == "response.output_text.delta" || e.Type == "response.reasoning_summary_text.delta" {
+				fmt.Print(e.Delta)
+			}
+		}))
+	}
+	if *display != "" {
+		var w, h int
+		if _, err := fmt.Sscanf(*display, "%dx%d", &w, &h); err != nil {
+			log.Fatalf("invalid -display %q: %v", *display, err)
+		}
+		opts = append(opts, cu.WithDisplaySize(w, h))
+	}
+	if *cdp != "" {
+		opts = append(opts, cu.WithRemoteBrowser(*cdp))
+	}
+	if *headful {
+		opts = append(opts, cu.WithHeadless(false))
+	}
+	if *userdatadir != "" {
+		opts = append(opts, cu.WithUserDataDir(*userdatadir))
+	}
+	if *proxy != "" {
+		opts = append(opts, cu.WithProxy(*proxy))
+	}
+	if *userAgent != "" {
+		opts = append(opts, cu.WithUserAgent(*userAgent))
+	}
+	if *locale != "" {
+		opts = append(opts, cu.WithLocale(*locale))
+	}
+	if *timezone != "" {
+		opts = append(opts, cu.WithTimezone(*timezone))
+	}
+	if *cookieFile != "" {
+		cookies, err := cu.LoadCookies(*cookieFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, cu.WithCookies(cookies...))
+	}
+	if *exportCookies != "" {
+		opts = append(opts, cu.WithCookieExport(*exportCookies))
+	}
+	if *nosandbox {
+		opts = append(opts, cu.WithNoSandbox())
+	}
+	if *keepprofile {
+		opts = append(opts, cu.WithKeepProfile())
+	}
+	if *verify != "" {
+		opts = append(opts, cu.WithAnswerVerification(*verify, ""))
+	}
+	if *approve != "" {
+		var policy cu.ApprovalPolicy
+		for _, class := range strings.Split(*approve, ",") {
+			switch {
+			case class == cu.ApproveSubmit:
+				policy.Submit = true
+			case class == cu.ApprovePassword:
+				policy.Password = true
+			case class == cu.ApproveAll:
+				policy.Everything = true
+			case strings.HasPrefix(class, cu.ApproveEnter+":"):
+				policy.EnterDomains = append(policy.EnterDomains, strings.TrimPrefix(class, cu.ApproveEnter+":"))
+			default:
+				log.Fatalf("unknown -approve class %q", class)
+			}
+		}
+		opts = append(opts, cu.WithApproval(cu.TerminalApproval(os.Stdin, os.Stdout), policy))
+	}
+	if *maxTokens > 0 || *maxCost > 0 {
+		opts = append(opts, cu.WithBudget(cu.Budget{MaxTokens: *maxTokens, MaxCost: *maxCost}))
+	}
+	if *traceDir != "" {
+		opts = append(opts, cu.WithTraceRecorder(cu.NewTraceRecorder(*traceDir)))
+	}
+	if *turnTimeout > 0 {
+		opts = append(opts, cu.WithTurnTimeout(*turnTimeout))
+	}
+	if *tabs {
+		opts = append(opts, cu.WithTabSwitching())
+	}
+	if *resize {
+		opts = append(opts, cu.WithViewportResize())
+	}
+	if *budget {
+		opts = append(opts, cu.WithTurnBudget(true))
+	}
+	if len(navPolicy.Allow) > 0 || len(navPolicy.Block) > 0 {
+		opts = append(opts, cu.WithNavigationPolicy(navPolicy))
+	}
+	if len(privacy) > 0 {
+		opts = append(opts, cu.WithPrivacyZones(privacy...))
+	}
+	if *downloads != "" {
+		opts = append(opts, cu.WithDownloads(*downloads, map[string]cu.DownloadParser{"csv": cu.ParseCSV}))
+	}
+	if *attach != "" {
+		opts = append(opts, cu.WithUploadFiles(strings.Split(*attach, ",")...))
+	}
+	if *uploads {
+		opts = append(opts, cu.WithFileUploads())
+	}
+	if *demos != "" {
+		d, err := cu.LoadDemonstrations(*demos)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, cu.WithDemonstrations(d...))
+	}
+	if *steering != "" {
+		opts = append(opts, cu.WithSteering(*steering))
+	}
+	if *language != "" {
+		opts = append(opts, cu.WithAnswerLanguage(*language, 2))
+	}
+
+	if *replay != "" {
+		browser, err := cu.LaunchBrowser(ctx, opts...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer browser.Close()
+		if err := browser.Open(ctx, "about:blank"); err != nil {
+			log.Fatal(err)
+		}
+		steps, err := cu.ReplayTrace(ctx, browser, *replay, opts...)
+		for i, step := range steps {
+			fmt.Printf("#%d %s: %.1f%% of pixels changed\n", i+1, step.Action.Type, step.Diff*100)
+		}
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		fmt.Println("Done")
+		return
+	}
+
+	if *batchFile != "" {
+		data, err := os.ReadFile(*batchFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var tasks []cu.PoolTask
+		if err := json.Unmarshal(data, &tasks); err != nil {
+			log.Fatalf("invalid -batch file: %v", err)
+		}
+		pool := cu.NewPool(*concurrency, *maxturns, opts...)
+		pool.Shared = *shared
+		for i, r := range pool.RunAll(ctx, tasks) {
+			switch {
+			case r.Err != nil:
+				fmt.Printf("Task #%d failed: %v\n", i+1, r.Err)
+			default:
+				fmt.Printf("Task #%d (%s): %s\n", i+1, r.Result.Status, r.Result.Output)
+			}
+		}
+		return
+	}
+
+	if *taskFile != "" {
+		task, err := cu.LoadTask(*taskFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		results, err := cu.RunTask(ctx, task, *maxturns, opts...)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		for i, r := range results {
+			fmt.Printf("Answer #%d: %s\n", i+1, r.Output)
+		}
+		fmt.Println("Done")
+		return
+	}
+
+	if *samples > 1 {
+		vote, err := cu.SampleAnswers(ctx, *url, *prompt, *maxturns, *samples, nil, opts...)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		fmt.Printf("Answer (%d/%d votes): %s\n", vote.Votes, *samples, vote.Answer)
+		fmt.Println("Done")
+		return
+	}
+
+	var d *cu.Desktop
+	switch {
+	case *desktop:
+		d, err = cu.NewDesktop(ctx)
+	case *x11 != "":
+		d, err = cu.NewX11Desktop(ctx, *x11)
+	case *vnc != "":
+		d, err = cu.NewVNCDesktop(ctx, *vnc, os.Getenv("VNC_PASSWORD"))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var result *cu.Result
+	if d != nil {
+		defer d.Close()
+		result, err = cu.Run(ctx, d, *prompt, *maxturns, opts...)
+	} else if *resumeSession {
+		result, err = cu.ResumeBrowserUse(ctx, *sessionFile, opts...)
+	} else {
+		result, err = cu.BrowserUseResult(ctx, *url, *prompt, *maxturns, opts...)
+	}
+	if result != nil {
+		fmt.Println("Status :", result.Status)
+		fmt.Println("Turns  :", result.Turns)
+		fmt.Println("Actions:", len(result.Actions))
+		fmt.Println("Tokens :", result.Usage.TotalTokens)
+		fmt.Println("Answer :", result.Output)
+		if *export != "" {
+			if err := exportRun(*export, *prompt, result); err != nil {
+				log.Printf("Export failed: %v", err)
+			}
+		}
+	}
 	if err != nil {
 		log.Fatalf("Error: %v", err)
 	}
 	fmt.Println("Done")
 }
+
+// exportRun appends a completed run to a chat fine-tuning dataset
+func exportRun(path, instruction string, result *cu.Result) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	exporter, err := cu.NewDatasetExporter(f, cu.ExportChat, nil)
+	if err != nil {
+		return err
+	}
+	n, err := exporter.Add(instruction, result)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Exported %d examples to %s\n", n, path)
+	return nil
+}
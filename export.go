@@ -0,0 +1,195 @@
+package computeruse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ExportFormat selects the layout of exported training examples
+type ExportFormat string
+
+const (
+	// ExportChat writes one chat fine-tuning example per step: the instruction
+	// and the screenshot the model saw, labeled with the action it took. The
+	// last example is labeled with the final answer.
+	ExportChat ExportFormat = "chat"
+	// ExportTrajectory writes one line per run with all of its steps
+	ExportTrajectory ExportFormat = "trajectory"
+)
+
+// Scrubber removes personal data before it is exported. Nil functions leave
+// the data unchanged.
+type Scrubber struct {
+	// Text is applied to the instruction, typed text, URLs and the answer
+	Text func(string) string
+	// Image is applied to every screenshot, a PNG
+	Image func([]byte) ([]byte, error)
+}
+
+func (s *Scrubber) text(v string) string {
+	if s == nil || s.Text == nil || v == "" {
+		return v
+	}
+	return s.Text(v)
+}
+
+// image reads a screenshot file, scrubs it and returns it as a data URL
+func (s *Scrubber) image(file string) (string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("error reading screenshot: %w", err)
+	}
+	if s != nil && s.Image != nil {
+		if data, err = s.Image(data); err != nil {
+			return "", fmt.Errorf("error scrubbing %s: %w", file, err)
+		}
+	}
+	return dataURL(data), nil
+}
+
+// TrajectoryStep is an exported action with the screenshot it was decided on
+type TrajectoryStep struct {
+	Turn int `json:"turn"`
+	// Screenshot is the data URL of the screen before the action, empty for the first
+	Screenshot string `json:"screenshot,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Action     Action `json:"action"`
+}
+
+// Trajectory is the ExportTrajectory record of a run
+type Trajectory struct {
+	SessionID   string            `json:"session_id"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Instruction string            `json:"instruction"`
+	Steps       []TrajectoryStep  `json:"steps"`
+	// FinalScreenshot is the data URL of the screen the answer was given on
+	FinalScreenshot string `json:"final_screenshot,omitempty"`
+	Output          string `json:"output"`
+}
+
+// chatExample is an example in the chat fine-tuning format
+type chatExample struct {
+	Messages []chatMessage `json:"messages"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content any    `json:"content"`
+}
+
+type chatPart struct {
+	Type     string        `json:"type"`
+	Text     string        `json:"text,omitempty"`
+	ImageURL *chatImageURL `json:"image_url,omitempty"`
+}
+
+type chatImageURL struct {
+	URL string `json:"url"`
+}
+
+// DatasetExporter writes successful runs as JSON Lines training examples
+type DatasetExporter struct {
+	enc    *json.Encoder
+	format ExportFormat
+	scrub  *Scrubber
+}
+
+// NewDatasetExporter creates an exporter writing format to w, passing all data through scrub
+func NewDatasetExporter(w io.Writer, format ExportFormat, scrub *Scrubber) (*DatasetExporter, error) {
+	if format != ExportChat && format != ExportTrajectory {
+		return nil, fmt.Errorf("unknown export format: %s", format)
+	}
+	return &DatasetExporter{enc: json.NewEncoder(w), format: format, scrub: scrub}, nil
+}
+
+// Add exports the run of instruction that produced result and returns the
+// number of examples written. Runs that did not complete, or whose answer was
+// judged incorrect, are skipped.
+func (e *DatasetExporter) Add(instruction string, result *Result) (int, error) {
+	if result.Status != StatusCompleted || (result.Verification != nil && result.Verification.Verdict == VerdictIncorrect) {
+		return 0, nil
+	}
+	t, err := e.trajectory(instruction, result)
+	if err != nil {
+		return 0, err
+	}
+	if e.format == ExportTrajectory {
+		if err := e.enc.Encode(t); err != nil {
+			return 0, fmt.Errorf("error writing example: %w", err)
+		}
+		return 1, nil
+	}
+
+	n := 0
+	for _, step := range t.Steps {
+		label, err := json.Marshal(step.Action)
+		if err != nil {
+			return n, fmt.Errorf("error encoding action: %w", err)
+		}
+		if err := e.enc.Encode(chatStep(t.Instruction, step.URL, step.Screenshot, string(label))); err != nil {
+			return n, fmt.Errorf("error writing example: %w", err)
+		}
+		n++
+	}
+	if err := e.enc.Encode(chatStep(t.Instruction, e.scrub.text(result.FinalURL), t.FinalScreenshot, t.Output)); err != nil {
+		return n, fmt.Errorf("error writing example: %w", err)
+	}
+	return n + 1, nil
+}
+
+// trajectory collects the scrubbed steps of a run. The screen an action was
+// decided on is the screenshot taken after the previous action.
+func (e *DatasetExporter) trajectory(instruction string, result *Result) (*Trajectory, error) {
+	t := &Trajectory{
+		SessionID:   result.SessionID,
+		Tags:        result.Tags,
+		Instruction: e.scrub.text(instruction),
+		Output:      e.scrub.text(result.Output),
+	}
+	var screenshot, url string
+	for _, r := range result.Actions {
+		// Only computer actions are labeled; function calls do not change the screen
+		if r.Function != nil {
+			continue
+		}
+		action := r.Action
+		action.Text = e.scrub.text(action.Text)
+		action.URL = e.scrub.text(action.URL)
+		t.Steps = append(t.Steps, TrajectoryStep{Turn: r.Turn, Screenshot: screenshot, URL: url, Action: action})
+		screenshot, url = "", e.scrub.text(r.URL)
+		if r.Screenshot != "" {
+			image, err := e.scrub.image(r.Screenshot)
+			if err != nil {
+				return nil, err
+			}
+			screenshot = image
+		}
+	}
+	t.FinalScreenshot = screenshot
+	if result.FinalScreenshot != "" {
+		image, err := e.scrub.image(result.FinalScreenshot)
+		if err != nil {
+			return nil, err
+		}
+		t.FinalScreenshot = image
+	}
+	return t, nil
+}
+
+// chatStep builds a chat example labeling the screen with the assistant's reply
+func chatStep(instruction, url, screenshot, reply string) chatExample {
+	text := instruction
+	if url != "" {
+		text += "\n\nCurrent URL: " + url
+	}
+	content := []chatPart{{Type: "text", Text: text}}
+	if screenshot != "" {
+		content = append(content, chatPart{Type: "image_url", ImageURL: &chatImageURL{URL: screenshot}})
+	}
+	return chatExample{Messages: []chatMessage{
+		{Role: "user", Content: content},
+		{Role: "assistant", Content: reply},
+	}}
+}
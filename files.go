@@ -0,0 +1,107 @@
+package computeruse
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// File represents a file uploaded to the OpenAI Files API
+type File struct {
+	ID        string `json:"id"`
+	Object    string `json:"object"`
+	Bytes     int    `json:"bytes"`
+	CreatedAt int    `json:"created_at"`
+	Filename  string `json:"filename"`
+	Purpose   string `json:"purpose"`
+}
+
+// UploadFile uploads data to the OpenAI Files API and returns the created file
+// Parameters:
+// - data: The file contents
+// - filename: The name of the file (e.g., "screenshot.png")
+// - purpose: The intended purpose (e.g., "vision" for images)
+func UploadFile(ctx context.Context, data []byte, filename, purpose string) (*File, error) {
+	return uploadFile(ctx, defaultEndpoint(), data, filename, purpose)
+}
+
+// uploadFile uploads data to the Files API of an endpoint
+func uploadFile(ctx context.Context, ep endpoint, data []byte, filename, purpose string) (*File, error) {
+	if ep.apiKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := w.WriteField("purpose", purpose); err != nil {
+		return nil, fmt.Errorf("failed to write purpose field: %w", err)
+	}
+	part, err := w.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file field: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write file field: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish multipart body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", ep.baseURL+"/files", &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+ep.apiKey)
+
+	resp, err := ep.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("file upload failed with status code %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	var file File
+	if err := json.Unmarshal(respBody, &file); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal file: %w", err)
+	}
+	return &file, nil
+}
+
+// uploadedFiles maps the SHA-256 of the screenshots uploaded during a run to their file IDs
+type uploadedFiles map[[sha256.Size]byte]string
+
+// uploadScreenshot replaces the inline data URL of a computer output with an
+// uploaded file ID. Reused and unchanged frames were uploaded before, so their
+// file ID is taken from uploaded instead.
+func uploadScreenshot(ctx context.Context, ep endpoint, out *ComputerOutput, uploaded uploadedFiles) error {
+	data, err := decodeDataURL(out.ImageURL)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	id, ok := uploaded[sum]
+	if !ok {
+		file, err := uploadFile(ctx, ep, data, imageFileName(data), "vision")
+		if err != nil {
+			return fmt.Errorf("error uploading screenshot: %w", err)
+		}
+		id = file.ID
+		uploaded[sum] = id
+	}
+	out.FileID = id
+	out.ImageURL = ""
+	return nil
+}
@@ -0,0 +1,39 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUploadScreenshotReusesFileID(t *testing.T) {
+	uploads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		uploads++
+		fmt.Fprintf(w, `{"id":"file_%d","object":"file","purpose":"vision"}`, uploads)
+	}))
+	defer srv.Close()
+	ep := endpoint{client: srv.Client(), apiKey: "test", baseURL: srv.URL}
+
+	shot, err := (&fakeComputer{}).Screenshot(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	uploaded := uploadedFiles{}
+	var ids []string
+	for range 2 {
+		out := &ComputerOutput{Type: "input_image", ImageURL: dataURL(shot)}
+		if err := uploadScreenshot(context.Background(), ep, out, uploaded); err != nil {
+			t.Fatal(err)
+		}
+		if out.ImageURL != "" {
+			t.Error("uploaded screenshot kept its data URL")
+		}
+		ids = append(ids, out.FileID)
+	}
+	if uploads != 1 || ids[0] != "file_1" || ids[1] != "file_1" {
+		t.Errorf("uploads = %d, file IDs = %v; want one upload reused as file_1", uploads, ids)
+	}
+}
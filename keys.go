@@ -0,0 +1,183 @@
+package computeruse
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod/lib/input"
+)
+
+// keyAliases maps the key names models emit, following the computer-use
+// action vocabulary and xdotool, to the canonical names used by keyTable
+var keyAliases = map[string]string{
+	"cmd":          "meta",
+	"command":      "meta",
+	"super":        "meta",
+	"win":          "meta",
+	"windows":      "meta",
+	"control":      "ctrl",
+	"option":       "alt",
+	"opt":          "alt",
+	"return":       "enter",
+	"esc":          "escape",
+	"del":          "delete",
+	"bksp":         "backspace",
+	"back_space":   "backspace",
+	"ins":          "insert",
+	"arrowleft":    "left",
+	"arrowright":   "right",
+	"arrowup":      "up",
+	"arrowdown":    "down",
+	"pageup":       "page_up",
+	"pagedown":     "page_down",
+	"pgup":         "page_up",
+	"pgdn":         "page_down",
+	"prior":        "page_up",
+	"next":         "page_down",
+	"spacebar":     "space",
+	"caps_lock":    "capslock",
+	"num_lock":     "numlock",
+	"scroll_lock":  "scrolllock",
+	"break":        "pause",
+	"print":        "printscreen",
+	"print_screen": "printscreen",
+	"prtsc":        "printscreen",
+	"menu":         "contextmenu",
+	"apps":         "contextmenu",
+	"context_menu": "contextmenu",
+	"plus":         "+",
+	"minus":        "-",
+	"equal":        "=",
+	"comma":        ",",
+	"period":       ".",
+	"slash":        "/",
+	"divide":       "/",
+	"backslash":    "\\",
+	"semicolon":    ";",
+	"quote":        "'",
+	"apostrophe":   "'",
+	"backquote":    "`",
+	"grave":        "`",
+}
+
+// keyTable maps canonical key names to browser keys
+var keyTable = map[string]input.Key{
+	"enter":       input.Enter,
+	"delete":      input.Delete,
+	"backspace":   input.Backspace,
+	"tab":         input.Tab,
+	"escape":      input.Escape,
+	"space":       input.Space,
+	"insert":      input.Insert,
+	"left":        input.ArrowLeft,
+	"right":       input.ArrowRight,
+	"up":          input.ArrowUp,
+	"down":        input.ArrowDown,
+	"page_up":     input.PageUp,
+	"page_down":   input.PageDown,
+	"home":        input.Home,
+	"end":         input.End,
+	"capslock":    input.CapsLock,
+	"numlock":     input.NumLock,
+	"scrolllock":  input.ScrollLock,
+	"pause":       input.Pause,
+	"printscreen": input.PrintScreen,
+	"contextmenu": input.ContextMenu,
+	"f1":          input.F1,
+	"f2":          input.F2,
+	"f3":          input.F3,
+	"f4":          input.F4,
+	"f5":          input.F5,
+	"f6":          input.F6,
+	"f7":          input.F7,
+	"f8":          input.F8,
+	"f9":          input.F9,
+	"f10":         input.F10,
+	"f11":         input.F11,
+	"f12":         input.F12,
+	"ctrl":        input.ControlLeft,
+	"shift":       input.ShiftLeft,
+	"alt":         input.AltLeft,
+	"meta":        input.MetaLeft,
+}
+
+// splitChords expands keys written as chords, e.g. "ctrl+a" or "CTRL+SHIFT+T",
+// into their parts; a lone "+" is the plus key
+func splitChords(keys []string) []string {
+	var split []string
+	for _, key := range keys {
+		if len(key) < 2 || !strings.Contains(key, "+") {
+			split = append(split, key)
+			continue
+		}
+		parts := strings.Split(key, "+")
+		for i, part := range parts {
+			if part == "" {
+				// "ctrl++" ends with the plus key
+				if i == len(parts)-1 {
+					split = append(split, "+")
+				}
+				continue
+			}
+			split = append(split, part)
+		}
+	}
+	return split
+}
+
+// isModifier reports whether a canonical key name is a modifier
+func isModifier(key string) bool {
+	return key == "ctrl" || key == "shift" || key == "alt" || key == "meta"
+}
+
+// NormalizeKeys splits chords such as "ctrl+a", converts the keys of a
+// keypress action to canonical lower-case names and maps shortcut modifiers to the platform of the browser: CMD becomes
+// CTRL outside macOS, where only CTRL shortcuts exist, and CTRL+<letter>
+// becomes CMD+<letter> on macOS.
+func NormalizeKeys(keys []string, mac bool) []string {
+	keys = splitChords(keys)
+	normalized := make([]string, len(keys))
+	hasLetter := false
+	for i, key := range keys {
+		k := strings.ToLower(key)
+		if alias, ok := keyAliases[k]; ok {
+			k = alias
+		}
+		if utf8.RuneCountInString(k) == 1 {
+			hasLetter = true
+		}
+		normalized[i] = k
+	}
+	for i, k := range normalized {
+		switch {
+		case k == "meta" && !mac:
+			normalized[i] = "ctrl"
+		case k == "ctrl" && mac && hasLetter:
+			normalized[i] = "meta"
+		}
+	}
+	return normalized
+}
+
+// lookupKey returns the browser key for a canonical key name or single character
+func lookupKey(name string) (input.Key, bool) {
+	if key, ok := keyTable[name]; ok {
+		return key, true
+	}
+	if r, size := utf8.DecodeRuneInString(name); size == len(name) && r < utf8.RuneSelf {
+		key := input.Key(r)
+		return key, knownKey(key)
+	}
+	return 0, false
+}
+
+// knownKey reports whether rod has a definition for key; Key.Info panics otherwise
+func knownKey(key input.Key) (known bool) {
+	defer func() {
+		if recover() != nil {
+			known = false
+		}
+	}()
+	key.Info()
+	return true
+}
@@ -0,0 +1,150 @@
+package computeruse
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// languageScripts maps language names and codes to the Unicode scripts their text is written in
+var languageScripts = map[string][]*unicode.RangeTable{
+	"japanese":   {unicode.Hiragana, unicode.Katakana, unicode.Han},
+	"ja":         {unicode.Hiragana, unicode.Katakana, unicode.Han},
+	"chinese":    {unicode.Han},
+	"zh":         {unicode.Han},
+	"korean":     {unicode.Hangul},
+	"ko":         {unicode.Hangul},
+	"russian":    {unicode.Cyrillic},
+	"ru":         {unicode.Cyrillic},
+	"ukrainian":  {unicode.Cyrillic},
+	"uk":         {unicode.Cyrillic},
+	"greek":      {unicode.Greek},
+	"el":         {unicode.Greek},
+	"arabic":     {unicode.Arabic},
+	"ar":         {unicode.Arabic},
+	"hebrew":     {unicode.Hebrew},
+	"he":         {unicode.Hebrew},
+	"thai":       {unicode.Thai},
+	"th":         {unicode.Thai},
+	"hindi":      {unicode.Devanagari},
+	"hi":         {unicode.Devanagari},
+	"english":    {unicode.Latin},
+	"en":         {unicode.Latin},
+	"french":     {unicode.Latin},
+	"fr":         {unicode.Latin},
+	"german":     {unicode.Latin},
+	"de":         {unicode.Latin},
+	"spanish":    {unicode.Latin},
+	"es":         {unicode.Latin},
+	"italian":    {unicode.Latin},
+	"it":         {unicode.Latin},
+	"portuguese": {unicode.Latin},
+	"pt":         {unicode.Latin},
+	"dutch":      {unicode.Latin},
+	"nl":         {unicode.Latin},
+}
+
+// stopWords are the most common short words of the Latin-script languages,
+// which share a script and are told apart by their words instead
+var stopWords = map[string]map[string]bool{
+	"en": wordSet("the and of to is that it was for with are this have from not be by which you they has were will would there their what about"),
+	"fr": wordSet("le la les des et est une du que qui pas pour dans sur avec sont ce cette au aux il elle nous vous mais ou plus"),
+	"de": wordSet("der die das und ist nicht ein eine zu den mit von sich auf für dem auch es sind wird werden noch nach bei oder aus wie"),
+	"es": wordSet("el los las del y es una que por con para se no lo su al como más pero sus está son este esta también"),
+	"it": wordSet("il gli della di che e è non per una sono con del alla anche come più questo questa nel ma ha dei delle"),
+	"pt": wordSet("o os as da do das dos e é não um uma que para com em no na por mais são seu sua também ao"),
+	"nl": wordSet("de het een en van is dat niet op te zijn voor met ook maar er dit die wordt aan bij heeft naar om"),
+}
+
+// latinLanguages maps language names and codes to the keys of stopWords
+var latinLanguages = map[string]string{
+	"english": "en", "en": "en",
+	"french": "fr", "fr": "fr",
+	"german": "de", "de": "de",
+	"spanish": "es", "es": "es",
+	"italian": "it", "it": "it",
+	"portuguese": "pt", "pt": "pt",
+	"dutch": "nl", "nl": "nl",
+}
+
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// languageInstruction is appended to the task so the model answers in the requested language
+func languageInstruction(lang string) string {
+	return fmt.Sprintf("Write your final answer in %s, regardless of the language of the web pages you visit.", lang)
+}
+
+// languageValidator asks the model to restate answers given in the wrong language
+func languageValidator(lang string, retries int) *answerValidator {
+	return &answerValidator{
+		name: "language",
+		check: func(answer string) error {
+			if !matchesLanguage(answer, lang) {
+				return fmt.Errorf("answer is not written in %s", lang)
+			}
+			return nil
+		},
+		repair: func(error) string {
+			return fmt.Sprintf("Your answer is not written in %s. Please restate your final answer in %s.", lang, lang)
+		},
+		retries: retries,
+	}
+}
+
+// matchesLanguage reports whether most letters of text belong to the scripts
+// of lang and, for Latin-script languages, whether the text is not clearly
+// written in another of them. Unknown languages are always accepted since
+// they cannot be checked.
+func matchesLanguage(text, lang string) bool {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	scripts, ok := languageScripts[lang]
+	if !ok {
+		return true
+	}
+	if code, ok := latinLanguages[lang]; ok && writtenInOtherLanguage(text, code) {
+		return false
+	}
+
+	letters, matching := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.IsOneOf(scripts, r) {
+			matching++
+		}
+	}
+	if letters == 0 {
+		return true
+	}
+	// Allow some foreign names and titles quoted from the page
+	return matching*2 >= letters
+}
+
+// writtenInOtherLanguage reports whether the common words of text belong
+// clearly more to another Latin-script language than to code. Short answers
+// such as numbers and names have too few common words to judge and pass.
+func writtenInOtherLanguage(text, code string) bool {
+	counts := map[string]int{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, w := range words {
+		for lang, set := range stopWords {
+			if set[w] {
+				counts[lang]++
+			}
+		}
+	}
+	for lang, n := range counts {
+		if lang != code && n >= 3 && n > 2*counts[code] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package computeruse
+
+import "testing"
+
+func TestMatchesLanguage(t *testing.T) {
+	tests := []struct {
+		text, lang string
+		want       bool
+	}{
+		{"The price of the product is 25 euros and delivery is free.", "English", true},
+		{"Le prix du produit est de 25 euros et la livraison est gratuite.", "English", false},
+		{"Der Preis des Produkts ist 25 Euro und die Lieferung ist kostenlos.", "en", false},
+		{"Le prix du produit est de 25 euros et la livraison est gratuite.", "French", true},
+		{"The price of the product is 25 euros and delivery is free.", "fr", false},
+		{"25 euros", "English", true},
+		{"Galeries Lafayette", "English", true},
+		{"製品の価格は25ユーロです。", "Japanese", true},
+		{"The price is 25 euros.", "Japanese", false},
+		{"Anything at all", "Klingon", true},
+	}
+	for _, tt := range tests {
+		if got := matchesLanguage(tt.text, tt.lang); got != tt.want {
+			t.Errorf("matchesLanguage(%q, %q) = %t, want %t", tt.text, tt.lang, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// Memory is a key-value store the model can read and write during a session,
+// e.g. to carry an order number found on one page to a form on another
+type Memory struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+// NewMemory returns an empty memory
+func NewMemory() *Memory {
+	return &Memory{values: map[string]string{}}
+}
+
+// Get returns the value stored under key
+func (m *Memory) Get(key string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.values[key]
+	return v, ok
+}
+
+// Set stores value under key
+func (m *Memory) Set(key, value string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+}
+
+// All returns a copy of every stored value
+func (m *Memory) All() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return maps.Clone(m.values)
+}
+
+// snapshot returns the stored values, or nil for a nil memory
+func (m *Memory) snapshot() map[string]string {
+	if m == nil {
+		return nil
+	}
+	return m.All()
+}
+
+// MarshalJSON encodes the stored values as a JSON object
+func (m *Memory) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.All())
+}
+
+// UnmarshalJSON restores the stored values from a JSON object
+func (m *Memory) UnmarshalJSON(data []byte) error {
+	values := map[string]string{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values = values
+	return nil
+}
+
+// memoryTools returns the memory_write and memory_read function tools backed by m
+func memoryTools(m *Memory) []functionTool {
+	write := functionTool{
+		tool: Tool{
+			Type:        "function",
+			Name:        "memory_write",
+			Description: "Remember a value under a key for later steps of the task, e.g. an order number to enter on another page.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"key":   map[string]any{"type": "string"},
+					"value": map[string]any{"type": "string"},
+				},
+				"required": []string{"key", "value"},
+			},
+		},
+		call: func(ctx context.Context, c Computer, arguments string) (string, error) {
+			var args struct {
+				Key   string `json:"key"`
+				Value string `json:"value"`
+			}
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return "", fmt.Errorf("invalid memory_write arguments: %w", err)
+			}
+			m.Set(args.Key, args.Value)
+			return "stored", nil
+		},
+	}
+	read := functionTool{
+		tool: Tool{
+			Type:        "function",
+			Name:        "memory_read",
+			Description: "Read a value remembered with memory_write. Omit key to list everything remembered.",
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"key": map[string]any{"type": "string"},
+				},
+			},
+		},
+		call: func(ctx context.Context, c Computer, arguments string) (string, error) {
+			var args struct {
+				Key string `json:"key"`
+			}
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return "", fmt.Errorf("invalid memory_read arguments: %w", err)
+			}
+			if args.Key == "" {
+				out, err := json.Marshal(m)
+				return string(out), err
+			}
+			v, ok := m.Get(args.Key)
+			if !ok {
+				return "", fmt.Errorf("nothing remembered under %q", args.Key)
+			}
+			return v, nil
+		},
+	}
+	return []functionTool{write, read}
+}
@@ -0,0 +1,14 @@
+package computeruse
+
+import "testing"
+
+func TestWithMemoryNilIsPerConfig(t *testing.T) {
+	opt := WithMemory(nil)
+	a, b := newConfig([]Option{opt}), newConfig([]Option{opt})
+	if a.memory == nil || b.memory == nil {
+		t.Fatal("WithMemory(nil) did not create a memory")
+	}
+	if a.memory == b.memory {
+		t.Error("configs built from one WithMemory(nil) option share a memory")
+	}
+}
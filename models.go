@@ -0,0 +1,93 @@
+package computeruse
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// DefaultModel is the computer-use model used when none is configured
+const DefaultModel = "computer-use-preview-2025-03-11"
+
+// ModelInfo describes the capabilities of a computer-use model
+type ModelInfo struct {
+	ID               string   `json:"id"`
+	Environments     []string `json:"environments"`
+	MaxDisplayWidth  int      `json:"max_display_width"`
+	MaxDisplayHeight int      `json:"max_display_height"`
+	// OptimalDisplayWidth and OptimalDisplayHeight are the screenshot size the
+	// model works best with; larger screenshots are downscaled to fit
+	OptimalDisplayWidth  int `json:"optimal_display_width,omitempty"`
+	OptimalDisplayHeight int `json:"optimal_display_height,omitempty"`
+	// Pricing is used to enforce cost budgets
+	Pricing *ModelPricing `json:"pricing,omitempty"`
+}
+
+var (
+	modelsMu sync.RWMutex
+	models   = map[string]ModelInfo{}
+)
+
+func init() {
+	for _, id := range []string{"computer-use-preview", "computer-use-preview-2025-03-11"} {
+		RegisterModel(ModelInfo{
+			ID:               id,
+			Environments:     []string{"browser", "mac", "windows", "ubuntu"},
+			MaxDisplayWidth:  3840,
+			MaxDisplayHeight: 2160,
+			// Screenshots larger than this are downscaled by default
+			OptimalDisplayWidth:  1024,
+			OptimalDisplayHeight: 768,
+			Pricing:              &ModelPricing{Input: 3, Output: 12},
+		})
+	}
+}
+
+// RegisterModel adds or replaces a model in the registry
+func RegisterModel(info ModelInfo) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	models[info.ID] = info
+}
+
+// LookupModel returns the registered capabilities of a model
+func LookupModel(id string) (ModelInfo, bool) {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+	info, ok := models[id]
+	return info, ok
+}
+
+// LoadModelRegistry registers the models listed in a JSON file, so new model IDs
+// can be used without code changes
+func LoadModelRegistry(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading model registry: %w", err)
+	}
+	var infos []ModelInfo
+	if err := json.Unmarshal(data, &infos); err != nil {
+		return fmt.Errorf("error parsing model registry: %w", err)
+	}
+	for _, info := range infos {
+		RegisterModel(info)
+	}
+	return nil
+}
+
+// checkModel validates that a registered model supports the environment and display size
+func checkModel(id, environment string, width, height int) error {
+	info, ok := LookupModel(id)
+	if !ok {
+		return fmt.Errorf("unknown computer-use model: %s (register it with RegisterModel)", id)
+	}
+	if !slices.Contains(info.Environments, environment) {
+		return fmt.Errorf("model %s does not support the %s environment", id, environment)
+	}
+	if (info.MaxDisplayWidth > 0 && width > info.MaxDisplayWidth) || (info.MaxDisplayHeight > 0 && height > info.MaxDisplayHeight) {
+		return fmt.Errorf("model %s supports displays up to %dx%d, got %dx%d", id, info.MaxDisplayWidth, info.MaxDisplayHeight, width, height)
+	}
+	return nil
+}
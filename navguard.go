@@ -0,0 +1,141 @@
+package computeruse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// ErrBlockedNavigation is matched by the error returned when a run lands on a
+// URL its NavigationPolicy does not permit and the policy is to abort
+var ErrBlockedNavigation = errors.New("navigation to a blocked URL")
+
+// BlockedNavigationError reports the URL that was blocked
+type BlockedNavigationError struct {
+	URL string
+}
+
+func (e *BlockedNavigationError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrBlockedNavigation, e.URL)
+}
+
+func (e *BlockedNavigationError) Unwrap() error {
+	return ErrBlockedNavigation
+}
+
+// BlockedNavigation selects what happens when a blocked URL is reached
+type BlockedNavigation string
+
+const (
+	// NavigateBack returns to the last permitted URL and tells the model
+	NavigateBack BlockedNavigation = "back"
+	// AbortNavigation fails the run with ErrBlockedNavigation
+	AbortNavigation BlockedNavigation = "abort"
+)
+
+// NavigationPolicy restricts the sites a run may visit. Patterns are hostnames,
+// which also match their subdomains, or globs such as "*.example.com". With an
+// Allow list only matching hosts are permitted; Block always wins over Allow.
+// Non-network pages such as about:blank are always permitted.
+type NavigationPolicy struct {
+	Allow     []string
+	Block     []string
+	OnBlocked BlockedNavigation
+}
+
+// Permits reports whether rawURL may be visited
+func (p *NavigationPolicy) Permits(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return u.Scheme == "about" || u.Scheme == "data"
+	}
+	host := u.Hostname()
+	for _, pattern := range p.Block {
+		if hostMatches(host, pattern) {
+			return false
+		}
+	}
+	if len(p.Allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.Allow {
+		if hostMatches(host, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostMatches matches a host against a hostname or glob pattern
+func hostMatches(host, pattern string) bool {
+	pattern = strings.ToLower(pattern)
+	if strings.ContainsAny(pattern, "*?[") {
+		ok, _ := path.Match(pattern, strings.ToLower(host))
+		return ok
+	}
+	return domainMatches(host, pattern)
+}
+
+// navigationGuard enforces a NavigationPolicy and remembers where to go back to
+type navigationGuard struct {
+	policy      *NavigationPolicy
+	lastAllowed string
+}
+
+// newNavigationGuard returns nil when no policy is configured
+func newNavigationGuard(policy *NavigationPolicy) *navigationGuard {
+	if policy == nil {
+		return nil
+	}
+	return &navigationGuard{policy: policy}
+}
+
+// check records a permitted URL and reports whether rawURL is blocked
+func (g *navigationGuard) check(rawURL string) bool {
+	if g == nil || rawURL == "" {
+		return false
+	}
+	if g.policy.Permits(rawURL) {
+		g.lastAllowed = rawURL
+		return false
+	}
+	return true
+}
+
+// blockedMessage tells the model that it was returned from a blocked page
+func blockedMessage(blocked, back string) string {
+	return fmt.Sprintf("The page %s is not permitted by the navigation policy, so you were returned to %s. "+
+		"Stay on permitted sites and find another way to complete the task.", blocked, back)
+}
+
+// enforce handles a blocked page according to the policy: it either fails with
+// a BlockedNavigationError or navigates back to the last permitted URL, updates
+// out with the new screen and returns a message for the model
+func (g *navigationGuard) enforce(ctx context.Context, c Computer, cfg *config, scale screenScale, out *ComputerOutput) (string, error) {
+	blocked := out.CurrentURL
+	nav, ok := c.(navigator)
+	if g.policy.OnBlocked == AbortNavigation || g.lastAllowed == "" || !ok {
+		return "", &BlockedNavigationError{URL: blocked}
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.actionTimeout)
+	defer cancel()
+	if err := nav.Navigate(ctx, g.lastAllowed); err != nil {
+		return "", errors.Join(&BlockedNavigationError{URL: blocked}, err)
+	}
+	screenshot, err := privateScreenshot(ctx, c, cfg.privacySelectors)
+	if err != nil {
+		return "", err
+	}
+	if screenshot, err = scale.shrink(screenshot); err != nil {
+		return "", err
+	}
+	out.ImageURL = dataURL(screenshot)
+	out.CurrentURL = g.lastAllowed
+	return blockedMessage(blocked, g.lastAllowed), nil
+}
@@ -0,0 +1,52 @@
+package computeruse
+
+import "fmt"
+
+// NavigationLoopError is returned when the agent keeps returning to the same page
+type NavigationLoopError struct {
+	URL    string
+	Visits int
+}
+
+func (e *NavigationLoopError) Error() string {
+	return fmt.Sprintf("navigation loop detected: visited %s %d times", e.URL, e.Visits)
+}
+
+// navigationTracker counts arrivals at each URL to detect navigation loops
+type navigationTracker struct {
+	warnAfter  int
+	abortAfter int
+	last       string
+	visits     map[string]int
+}
+
+// newNavigationTracker returns nil when loop detection is disabled
+func newNavigationTracker(warnAfter, abortAfter int) *navigationTracker {
+	if warnAfter <= 0 && abortAfter <= 0 {
+		return nil
+	}
+	return &navigationTracker{
+		warnAfter:  warnAfter,
+		abortAfter: abortAfter,
+		visits:     map[string]int{},
+	}
+}
+
+// visit records the current URL and returns a corrective message when the
+// warning threshold is reached, or an error when the abort threshold is reached
+func (t *navigationTracker) visit(url string) (string, error) {
+	if t == nil || url == "" || url == t.last {
+		return "", nil
+	}
+	t.last = url
+	t.visits[url]++
+	n := t.visits[url]
+
+	if t.abortAfter > 0 && n >= t.abortAfter {
+		return "", &NavigationLoopError{URL: url, Visits: n}
+	}
+	if t.warnAfter > 0 && n >= t.warnAfter {
+		return fmt.Sprintf("You have been on %s %d times already and seem to be going in circles. Try a different approach.", url, n), nil
+	}
+	return "", nil
+}
@@ -0,0 +1,136 @@
+package computeruse
+
+import (
+	"sync"
+	"time"
+)
+
+// ObservationKind is the kind of state change an Observation reports
+type ObservationKind string
+
+const (
+	// ObservedAction is sent before a computer action is executed
+	ObservedAction ObservationKind = "action"
+	// ObservedFrame is sent with every new screenshot
+	ObservedFrame ObservationKind = "frame"
+	// ObservedURL is sent when the page URL changes
+	ObservedURL ObservationKind = "url"
+)
+
+// Observation is a state change of a running session
+type Observation struct {
+	Kind   ObservationKind
+	Time   time.Time
+	Turn   int
+	Action *Action
+	// Image is the PNG of a frame and File where it was saved
+	Image []byte
+	File  string
+	URL   string
+	// Dropped counts the observations discarded for this subscriber since the
+	// previous delivered one, because it did not keep up
+	Dropped int
+}
+
+// Observer mirrors sessions to read-only subscribers such as viewers. A slow
+// subscriber never blocks the session: when its buffer is full the oldest
+// pending observation is dropped, so it always catches up to the latest state.
+type Observer struct {
+	mu      sync.Mutex
+	subs    map[*subscriber]struct{}
+	lastURL string
+}
+
+type subscriber struct {
+	ch      chan Observation
+	dropped int
+}
+
+// NewObserver creates an observer; attach it to runs with WithObserver
+func NewObserver() *Observer {
+	return &Observer{subs: make(map[*subscriber]struct{})}
+}
+
+// Subscribe returns a channel receiving observations, buffering up to buffer
+// of them, and a function that ends the subscription and closes the channel
+func (o *Observer) Subscribe(buffer int) (<-chan Observation, func()) {
+	s := &subscriber{ch: make(chan Observation, max(buffer, 1))}
+	o.mu.Lock()
+	o.subs[s] = struct{}{}
+	o.mu.Unlock()
+	var once sync.Once
+	return s.ch, func() {
+		once.Do(func() {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			if _, ok := o.subs[s]; ok {
+				delete(o.subs, s)
+				close(s.ch)
+			}
+		})
+	}
+}
+
+// Close ends all subscriptions
+func (o *Observer) Close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for s := range o.subs {
+		delete(o.subs, s)
+		close(s.ch)
+	}
+}
+
+// publish delivers an observation to every subscriber without blocking
+func (o *Observer) publish(ob Observation) {
+	if o == nil {
+		return
+	}
+	ob.Time = time.Now()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for s := range o.subs {
+		ob.Dropped = s.dropped
+		select {
+		case s.ch <- ob:
+			s.dropped = 0
+			continue
+		default:
+		}
+		// Make room by discarding the oldest observation
+		select {
+		case <-s.ch:
+			s.dropped++
+		default:
+		}
+		ob.Dropped = s.dropped
+		select {
+		case s.ch <- ob:
+			s.dropped = 0
+		default:
+			s.dropped++
+		}
+	}
+}
+
+func (o *Observer) action(turn int, action Action) {
+	o.publish(Observation{Kind: ObservedAction, Turn: turn, Action: &action})
+}
+
+// frame publishes a screenshot, preceded by a URL observation when the page changed
+func (o *Observer) frame(turn int, file string, out *ComputerOutput) {
+	if o == nil {
+		return
+	}
+	o.mu.Lock()
+	changed := out.CurrentURL != "" && out.CurrentURL != o.lastURL
+	if changed {
+		o.lastURL = out.CurrentURL
+	}
+	o.mu.Unlock()
+	if changed {
+		o.publish(Observation{Kind: ObservedURL, Turn: turn, URL: out.CurrentURL})
+	}
+	image, _ := decodeDataURL(out.ImageURL)
+	o.publish(Observation{Kind: ObservedFrame, Turn: turn, Image: image, File: file, URL: out.CurrentURL})
+}
@@ -2,44 +2,88 @@ package computeruse
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"strings"
 )
 
 // Request represents the structure for sending requests to the OpenAI API
 type Request struct {
-	Model              string  `json:"model"`
-	Input              []Input `json:"input"`
-	Text               *Text   `json:"text,omitempty"`
-	Tools              []Tool  `json:"tools,omitempty"`
-	Temperature        float64 `json:"temperature,omitempty"`
-	MaxOutputTokens    int     `json:"max_output_tokens,omitempty"`
-	TopP               float64 `json:"top_p,omitempty"`
-	Stream             bool    `json:"stream,omitempty"`
-	Store              bool    `json:"store,omitempty"`
-	Reasoning          any     `json:"reasoning,omitempty"`
-	Truncation         string  `json:"truncation,omitempty"`
-	PreviousResponseID string  `json:"previous_response_id,omitempty"`
+	Model              string            `json:"model"`
+	Input              []Input           `json:"input"`
+	Text               *Text             `json:"text,omitempty"`
+	Tools              []Tool            `json:"tools,omitempty"`
+	Temperature        float64           `json:"temperature,omitempty"`
+	MaxOutputTokens    int               `json:"max_output_tokens,omitempty"`
+	TopP               float64           `json:"top_p,omitempty"`
+	Stream             bool              `json:"stream,omitempty"`
+	Store              bool              `json:"store,omitempty"`
+	Reasoning          any               `json:"reasoning,omitempty"`
+	Truncation         string            `json:"truncation,omitempty"`
+	PreviousResponseID string            `json:"previous_response_id,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
 }
 
 // Input represents an input message in the request
+// Output holds a *ComputerOutput for computer_call_output items and a string for function_call_output items
+// Content holds a string, or []ContentPart for messages mixing text and images
 type Input struct {
-	Type                     string          `json:"type,omitempty"`
-	CallID                   string          `json:"call_id,omitempty"`
-	Output                   *ComputerOutput `json:"output,omitempty"`
-	Role                     string          `json:"role,omitempty"`
-	Content                  string          `json:"content,omitempty"`
-	AcknowledgedSafetyChecks []SafetyCheck   `json:"acknowledged_safety_checks,omitempty"`
+	Type                     string        `json:"type,omitempty"`
+	CallID                   string        `json:"call_id,omitempty"`
+	Output                   any           `json:"output,omitempty"`
+	Role                     string        `json:"role,omitempty"`
+	Content                  any           `json:"content,omitempty"`
+	AcknowledgedSafetyChecks []SafetyCheck `json:"acknowledged_safety_checks,omitempty"`
+}
+
+// NewComputerCallOutput builds the computer_call_output item answering a computer
+// call with the screenshot taken after the action, acknowledging the pending
+// safety checks of the call in the same item
+func NewComputerCallOutput(callID string, out *ComputerOutput, acknowledged ...SafetyCheck) Input {
+	return Input{
+		Type:                     "computer_call_output",
+		CallID:                   callID,
+		Output:                   out,
+		AcknowledgedSafetyChecks: acknowledged,
+	}
+}
+
+// NewFunctionCallOutput builds the function_call_output item answering a function call
+func NewFunctionCallOutput(callID, output string) Input {
+	return Input{
+		Type:   "function_call_output",
+		CallID: callID,
+		Output: output,
+	}
+}
+
+// ContentPart is a part of a multimodal input message
+type ContentPart struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
 }
 
 // ComputerOutput represents computer output data in the API interaction
+// The screenshot is either inline in ImageURL or uploaded and referenced by FileID
 type ComputerOutput struct {
 	Type       string `json:"type"`
-	ImageURL   string `json:"image_url"`
-	CurrentURL string `json:"current_url"`
+	ImageURL   string `json:"image_url,omitempty"`
+	FileID     string `json:"file_id,omitempty"`
+	CurrentURL string `json:"current_url,omitempty"`
+
+	// Viewport, Page, Tab and Content are not part of the API schema; they are sent to the model as text messages
+	Viewport *ViewportState `json:"-"`
+	Page     *PageMetadata  `json:"-"`
+	Tab      *TabInfo       `json:"-"`
+	Content  *PageContent   `json:"-"`
+	// Element is the DOM element hit by a click, recorded in the trajectory only
+	Element *ElementInfo `json:"-"`
+	// reused is set when ImageURL is the previous frame rather than a new screenshot
+	reused bool
 }
 
 // Text represents text format configuration
@@ -82,6 +126,16 @@ type Response struct {
 	Metadata           map[string]any `json:"metadata"`
 }
 
+// IncompleteReason returns why an incomplete response stopped, e.g. "max_output_tokens"
+func (r *Response) IncompleteReason() string {
+	if details, ok := r.IncompleteDetails.(map[string]any); ok {
+		if reason, ok := details["reason"].(string); ok {
+			return reason
+		}
+	}
+	return ""
+}
+
 // OutputItem represents an output item in the API response
 type OutputItem struct {
 	Type                string        `json:"type"`
@@ -91,7 +145,47 @@ type OutputItem struct {
 	Action              *Action       `json:"action,omitempty"`
 	Role                string        `json:"role,omitempty"`
 	Content             []any         `json:"content,omitempty"`
+	Name                string        `json:"name,omitempty"`
+	Arguments           string        `json:"arguments,omitempty"`
 	PendingSafetyChecks []SafetyCheck `json:"pending_safety_checks,omitempty"`
+	Summary             []SummaryPart `json:"summary,omitempty"`
+}
+
+// SummaryPart is a part of the summary of a reasoning output item
+type SummaryPart struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// reasoningSummary returns the summary text of the reasoning items in a response
+func reasoningSummary(response *Response) string {
+	var parts []string
+	for _, o := range response.Output {
+		if o.Type != "reasoning" {
+			continue
+		}
+		for _, p := range o.Summary {
+			if p.Text != "" {
+				parts = append(parts, p.Text)
+			}
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Text returns the concatenated output_text parts of a message output item
+func (o OutputItem) Text() string {
+	var sb strings.Builder
+	for _, c := range o.Content {
+		part, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if text, ok := part["text"].(string); ok {
+			sb.WriteString(text)
+		}
+	}
+	return sb.String()
 }
 
 // SafetyCheck represents a safety check in the API response
@@ -111,6 +205,19 @@ type Action struct {
 	Y       int      `json:"y,omitempty"`
 	ScrollX int      `json:"scroll_x,omitempty"`
 	ScrollY int      `json:"scroll_y,omitempty"`
+	Path    []Point  `json:"path,omitempty"`
+	URL     string   `json:"url,omitempty"`
+	// Width and Height are the new viewport size of a resize action
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Tab is the tab index of a switch_tab action, counting from 1
+	Tab int `json:"tab,omitempty"`
+}
+
+// Point is a point on the display, used by the path of a drag action
+type Point struct {
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 
 // Key represents a key-value pair
@@ -154,6 +261,16 @@ type UsageInfo struct {
 	TotalTokens         int                 `json:"total_tokens"`
 }
 
+// add returns the sum of two usages
+func (u UsageInfo) add(o UsageInfo) UsageInfo {
+	u.InputTokens += o.InputTokens
+	u.InputTokensDetails.CachedTokens += o.InputTokensDetails.CachedTokens
+	u.OutputTokens += o.OutputTokens
+	u.OutputTokensDetails.ReasoningTokens += o.OutputTokensDetails.ReasoningTokens
+	u.TotalTokens += o.TotalTokens
+	return u
+}
+
 // InputTokensDetails represents details about input tokens
 type InputTokensDetails struct {
 	CachedTokens int `json:"cached_tokens"`
@@ -166,10 +283,13 @@ type OutputTokensDetails struct {
 
 // Tool represents a tool configuration for the API
 type Tool struct {
-	Type          string `json:"type"`
-	DisplayWidth  int    `json:"display_width"`
-	DisplayHeight int    `json:"display_height"`
-	Environment   string `json:"environment"`
+	Type          string         `json:"type"`
+	DisplayWidth  int            `json:"display_width,omitempty"`
+	DisplayHeight int            `json:"display_height,omitempty"`
+	Environment   string         `json:"environment,omitempty"`
+	Name          string         `json:"name,omitempty"`
+	Description   string         `json:"description,omitempty"`
+	Parameters    map[string]any `json:"parameters,omitempty"`
 }
 
 // Responses sends a request to the OpenAI API and retrieves the response
@@ -177,67 +297,76 @@ type Tool struct {
 // - model: The model name to use (e.g., "gpt-4o")
 // - responseID: Previous response ID for conversation continuity
 // - input: Array of input messages
-func Responses(model string, responseID string, input []Input) (*Response, error) {
-	// Get API key from environment variable
-	apiKey := os.Getenv("OPENAI_API_KEY")
-	if apiKey == "" {
-		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+// - tools: Additional function tools offered next to the computer tool
+func Responses(model string, responseID string, input []Input, tools ...Tool) (*Response, error) {
+	return NewClient(WithModel(model)).Responses(context.Background(), responseID, input, tools...)
+}
+
+// CreateResponse sends a fully built request to the OpenAI API and retrieves the response
+// Timeouts are taken from the environment; see TimeoutsFromEnv.
+func CreateResponse(request Request) (*Response, error) {
+	return sendResponse(context.Background(), defaultEndpoint(), request)
+}
+
+// sendResponse posts request to the Responses API of an endpoint
+func sendResponse(ctx context.Context, ep endpoint, request Request) (*Response, error) {
+	resp, err := postResponses(ctx, ep, request)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	request := Request{
-		Model:              model,
-		Input:              input,
-		PreviousResponseID: responseID,
-		Truncation:         "auto",
+	// Read the response body
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	request.Tools = []Tool{
-		{
-			Type:          "computer-preview",
-			DisplayWidth:  1024,
-			DisplayHeight: 768,
-			Environment:   "browser",
-		},
+	// Parse the response
+	var response Response
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
+
+	return &response, nil
+}
+
+// postResponses sends request to the Responses API and returns the successful
+// HTTP response, whose body the caller must close
+func postResponses(ctx context.Context, ep endpoint, request Request) (*http.Response, error) {
+	if ep.apiKey == "" {
+		return nil, fmt.Errorf("OPENAI_API_KEY environment variable is not set")
+	}
+
 	requestBody, err := json.Marshal(request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://api.openai.com/v1/responses", bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", ep.baseURL+"/responses", bytes.NewBuffer(requestBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("Authorization", "Bearer "+ep.apiKey)
 
 	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := ep.client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
-	defer resp.Body.Close()
-
-	// Read the response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
 
 	// Return error if status code is not 200
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status code %d: %s", resp.StatusCode, string(body))
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		return nil, newAPIError(resp.StatusCode, resp.Header, body)
 	}
-
-	// Parse the response
-	var response Response
-	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &response, nil
+	return resp, nil
 }
 
 // NewComputerMessage creates a new user message with the given text
@@ -0,0 +1,71 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCallOutputSchema(t *testing.T) {
+	out := &ComputerOutput{
+		Type:       "input_image",
+		ImageURL:   "data:image/png;base64,AAAA",
+		CurrentURL: "https://example.com/",
+		Viewport:   &ViewportState{ScrollY: 100},
+		Page:       &PageMetadata{Title: "Example"},
+		Tab:        &TabInfo{Index: 1, Count: 2},
+		Content:    &PageContent{Title: "Example", Text: "secret"},
+		Element:    &ElementInfo{Tag: "button"},
+	}
+	check := SafetyCheck{ID: "sc_1", Code: "malicious_instructions", Message: "Check the page"}
+
+	tests := []struct {
+		name  string
+		input Input
+		want  string
+	}{
+		{
+			name:  "computer call output",
+			input: NewComputerCallOutput("call_1", out),
+			want: `{"type":"computer_call_output","call_id":"call_1",` +
+				`"output":{"type":"input_image","image_url":"data:image/png;base64,AAAA","current_url":"https://example.com/"}}`,
+		},
+		{
+			name:  "computer call output with acknowledged safety checks",
+			input: NewComputerCallOutput("call_2", out, check),
+			want: `{"type":"computer_call_output","call_id":"call_2",` +
+				`"output":{"type":"input_image","image_url":"data:image/png;base64,AAAA","current_url":"https://example.com/"},` +
+				`"acknowledged_safety_checks":[{"id":"sc_1","code":"malicious_instructions","message":"Check the page"}]}`,
+		},
+		{
+			name:  "computer call output with uploaded screenshot",
+			input: NewComputerCallOutput("call_3", &ComputerOutput{Type: "input_image", FileID: "file_1"}),
+			want:  `{"type":"computer_call_output","call_id":"call_3","output":{"type":"input_image","file_id":"file_1"}}`,
+		},
+		{
+			name:  "function call output",
+			input: NewFunctionCallOutput("call_4", `{"ok":true}`),
+			want:  `{"type":"function_call_output","call_id":"call_4","output":"{\"ok\":true}"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientResponsesRejectsInvalidOptions(t *testing.T) {
+	api := NewScriptedResponses(MessageResponse("resp_1", "done"))
+	client := NewClient(WithResponsesAPI(api), WithDisplaySize(0, 0))
+	if _, err := client.Responses(context.Background(), "", nil); err == nil || !strings.Contains(err.Error(), "invalid display size") {
+		t.Fatalf("Responses with an invalid option: err = %v", err)
+	}
+}
@@ -0,0 +1,881 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"time"
+)
+
+// Option configures optional behaviour of BrowserUse
+type Option func(*config)
+
+// config holds the settings collected from Options
+type config struct {
+	model             string
+	maxOutputTokens   int
+	truncation        string
+	actionTimeout     time.Duration
+	turnTimeout       time.Duration
+	tools             []functionTool
+	loopWarnAfter     int
+	loopAbortAfter    int
+	stuckRepeats      int
+	context           []Input
+	instructions      []string
+	validators        []*answerValidator
+	steering          string
+	domSnapshots      bool
+	uploadScreenshots bool
+	actionDelays      map[string]time.Duration
+	reuseScreenshot   map[string]bool
+	screenshots       *ScreenshotPipeline
+	humanPacing       bool
+	viewportInfo      bool
+	pageMetadata      bool
+	pageContext       *PageContext
+	memory            *Memory
+	taskRetries       int
+	critic            func(answer string) error
+	reset             func(ctx context.Context) error
+	resilience        Resilience
+	retryPolicy       RetryPolicy
+	checkpointPath    string
+	sessionFile       string
+	resume            *Checkpoint
+	artifactsDir      string
+	sessionID         string
+	safetyHandler     SafetyCheckHandler
+	approvalFunc      ApprovalFunc
+	approvalPolicy    ApprovalPolicy
+	events            *Events
+	turnBudget        bool
+	wrapUp            bool
+	navigationPolicy  *NavigationPolicy
+	outputFormat      *Text
+	outputTarget      any
+	observer          *Observer
+	trace             *TraceRecorder
+	budget            *Budget
+	// spent tracks the budget during a run
+	spent               *budgetTracker
+	cookies             []Cookie
+	downloadDir         string
+	downloadParsers     map[string]DownloadParser
+	uploadFiles         map[string]string
+	timeouts            *Timeouts
+	rateLimitWait       time.Duration
+	reasoningSummary    string
+	privacySelectors    []string
+	setupActions        []Action
+	verifyModel         string
+	verifyRubric        string
+	keepProfile         bool
+	onStream            func(StreamEvent)
+	apiKey              string
+	baseURL             string
+	httpClient          *http.Client
+	api                 ResponsesAPI
+	displayWidth        int
+	displayHeight       int
+	maxScreenshotWidth  int
+	maxScreenshotHeight int
+	environment         string
+	tags                map[string]string
+	saveStatePath       string
+	warmStart           *PageState
+	emulation           emulation
+	cookieExportPath    string
+	remoteURL           string
+	headless            bool
+	userDataDir         string
+	proxy               string
+	noSandbox           bool
+	launchFlags         []string
+	// errs collects invalid option values, reported when the run starts
+	errs []error
+}
+
+// newConfig applies the given options on top of the defaults
+func newConfig(opts []Option) *config {
+	c := &config{
+		model:         DefaultModel,
+		truncation:    "auto",
+		resilience:    Strict,
+		artifactsDir:  "screenshots",
+		actionTimeout: 30 * time.Second,
+		events:        ConsoleEvents(),
+		retryPolicy:   DefaultRetryPolicy,
+		headless:      true,
+		displayWidth:  1024,
+		displayHeight: 768,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	// API calls without their own client or timeouts take them from the environment
+	if c.api == nil && c.httpClient == nil && c.timeouts == nil {
+		if _, err := TimeoutsFromEnv(); err != nil {
+			c.errs = append(c.errs, err)
+		}
+	}
+	return c
+}
+
+// actionDelay returns the post-action delay for an action type, falling back to the "*" entry
+func (c *config) actionDelay(actionType string) time.Duration {
+	if d, ok := c.actionDelays[actionType]; ok {
+		return d
+	}
+	return c.actionDelays["*"]
+}
+
+// reasoningParams returns the reasoning request parameters, or nil when summaries are off
+func reasoningParams(c *config) any {
+	if c.reasoningSummary == "" {
+		return nil
+	}
+	return map[string]any{"summary": c.reasoningSummary}
+}
+
+// WithScrollHelper exposes the scroll_until function tool to the model, which
+// scrolls an infinite list until a condition is met in a single step
+func WithScrollHelper() Option {
+	return func(c *config) {
+		c.tools = append(c.tools, scrollUntilTool)
+	}
+}
+
+// WithTabSwitching offers the model a switch_tab tool to move between browser
+// tabs. New tabs, such as popups, become active on their own either way.
+func WithTabSwitching() Option {
+	return func(c *config) {
+		c.tools = append(c.tools, switchTabTool)
+	}
+}
+
+// WithViewportResize offers the model a resize_viewport tool to change the
+// browser viewport mid-session; screenshots and the declared display size
+// follow the new size
+func WithViewportResize() Option {
+	return func(c *config) {
+		c.tools = append(c.tools, resizeViewportTool)
+	}
+}
+
+// WithNavigationLoopDetection tracks visited URLs and tells the model to try a
+// different approach once it arrives at the same page warnAfter times, and aborts
+// the run with a *NavigationLoopError after abortAfter arrivals. Zero disables either step.
+func WithNavigationLoopDetection(warnAfter, abortAfter int) Option {
+	return func(c *config) {
+		c.loopWarnAfter = warnAfter
+		c.loopAbortAfter = abortAfter
+	}
+}
+
+// WithStuckDetection sends the model a nudge message when it emits the same
+// action repeats times in a row while the screenshot stays unchanged
+func WithStuckDetection(repeats int) Option {
+	return func(c *config) {
+		c.stuckRepeats = repeats
+	}
+}
+
+// WithAnswerLanguage requires the final answer to be written in lang (e.g. "English" or "ja").
+// Answers detected to be in another script, or for Latin-script languages in
+// another language judged by their common words, are sent back to the model
+// up to retries times.
+func WithAnswerLanguage(lang string, retries int) Option {
+	return func(c *config) {
+		c.instructions = append(c.instructions, languageInstruction(lang))
+		c.validators = append(c.validators, languageValidator(lang, retries))
+	}
+}
+
+// WithAnswerSchema requires the final answer to be JSON matching schema. Answers
+// that fail validation are sent back to the model for repair up to repairAttempts
+// times, after which the run fails.
+func WithAnswerSchema(schema map[string]any, repairAttempts int) Option {
+	return func(c *config) {
+		c.instructions = append(c.instructions, schemaInstruction(schema))
+		c.validators = append(c.validators, schemaValidator(schema, repairAttempts))
+	}
+}
+
+// WithStructuredOutput constrains the final answer to JSON matching schema
+// through the API's strict json_schema text format. name identifies the
+// schema to the API. The answer is also validated and repaired as with
+// WithAnswerSchema, for models that do not enforce the format.
+func WithStructuredOutput(name string, schema map[string]any) Option {
+	return func(c *config) {
+		c.outputFormat = &Text{Format: Format{
+			Type:   "json_schema",
+			Name:   name,
+			Strict: true,
+			Schema: schema,
+		}}
+		WithAnswerSchema(schema, 1)(c)
+	}
+}
+
+// WithOutputInto reflects the structured output schema from target, a pointer
+// to a struct (see SchemaFor), and decodes the final answer into it when the
+// run completes
+func WithOutputInto(target any) Option {
+	return func(c *config) {
+		if reflect.TypeOf(target) == nil || reflect.TypeOf(target).Kind() != reflect.Pointer {
+			c.errs = append(c.errs, fmt.Errorf("WithOutputInto: target must be a pointer, got %T", target))
+			return
+		}
+		schema, err := SchemaFor(target)
+		if err != nil {
+			c.errs = append(c.errs, fmt.Errorf("WithOutputInto: %w", err))
+			return
+		}
+		name := reflect.TypeOf(target).Elem().Name()
+		if name == "" {
+			name = "answer"
+		}
+		WithStructuredOutput(name, schema)(c)
+		c.outputTarget = target
+	}
+}
+
+// WithDemonstrations prepends recorded examples of similar tasks as context before the instruction
+func WithDemonstrations(demos ...Demonstration) Option {
+	return func(c *config) {
+		if len(demos) == 0 {
+			return
+		}
+		c.context = append(c.context, Input{
+			Role:    "user",
+			Content: demonstrationMessage(demos),
+		})
+	}
+}
+
+// WithSteering appends a short note (e.g. "remember: never leave example.com")
+// after the computer call outputs of every turn to keep long sessions on track
+func WithSteering(note string) Option {
+	return func(c *config) {
+		c.steering = note
+	}
+}
+
+// WithAPIKey sets the OpenAI API key instead of reading OPENAI_API_KEY
+func WithAPIKey(key string) Option {
+	return func(c *config) {
+		c.apiKey = key
+	}
+}
+
+// WithBaseURL sends API requests to an OpenAI-compatible endpoint, such as an
+// Azure OpenAI resource or a proxy, instead of OPENAI_BASE_URL or DefaultBaseURL
+func WithBaseURL(url string) Option {
+	return func(c *config) {
+		c.baseURL = url
+	}
+}
+
+// WithResponsesAPI sends model requests to api instead of the OpenAI API,
+// e.g. a ScriptedResponses in tests. Retries and rate limit pauses still apply.
+func WithResponsesAPI(api ResponsesAPI) Option {
+	return func(c *config) {
+		c.api = api
+	}
+}
+
+// WithHTTPClient sends API requests with client, taking precedence over WithAPITimeouts
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *config) {
+		c.httpClient = client
+	}
+}
+
+// WithDisplaySize sets the size of the browser launched by BrowserUse and of
+// the display declared to the model by Client.Responses (default 1024x768)
+func WithDisplaySize(width, height int) Option {
+	return func(c *config) {
+		if width <= 0 || height <= 0 {
+			c.errs = append(c.errs, fmt.Errorf("invalid display size %dx%d", width, height))
+			return
+		}
+		c.displayWidth = width
+		c.displayHeight = height
+	}
+}
+
+// WithEnvironment overrides the computer tool environment declared to the model
+// ("browser", "mac", "windows" or "ubuntu"), which defaults to the computer's own
+func WithEnvironment(env string) Option {
+	return func(c *config) {
+		c.environment = env
+	}
+}
+
+// WithModel selects the computer-use model, which must be registered in the model registry
+func WithModel(model string) Option {
+	return func(c *config) {
+		c.model = model
+	}
+}
+
+// WithMaxOutputTokens limits the tokens generated per response. Final answers cut
+// off by the limit are completed by asking the model to continue.
+func WithMaxOutputTokens(n int) Option {
+	return func(c *config) {
+		c.maxOutputTokens = n
+	}
+}
+
+// WithTruncation sets the context truncation strategy, "auto" (default) or "disabled"
+func WithTruncation(strategy string) Option {
+	return func(c *config) {
+		c.truncation = strategy
+	}
+}
+
+// WithActionTimeout sets the deadline for each browser action and screenshot
+// (default 30s). Actions that time out are reported to the model.
+func WithActionTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.actionTimeout = d
+	}
+}
+
+// WithTurnTimeout bounds each turn, the model request together with the
+// actions it returns, to d. A turn that runs out of time fails the run.
+func WithTurnTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.turnTimeout = d
+	}
+}
+
+// turnContext returns the context of one turn of a run under ctx
+func (c *config) turnContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.turnTimeout > 0 {
+		return context.WithTimeout(ctx, c.turnTimeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// WithDOMSnapshots saves an MHTML snapshot of the page next to each screenshot,
+// so reviewers can inspect the markup the agent saw
+func WithDOMSnapshots() Option {
+	return func(c *config) {
+		c.domSnapshots = true
+	}
+}
+
+// WithFileUploads uploads screenshots through the Files API and references them
+// by file ID instead of embedding base64 data URLs in every request
+func WithFileUploads() Option {
+	return func(c *config) {
+		c.uploadScreenshots = true
+	}
+}
+
+// WithActionDelays waits after each action before taking the screenshot, keyed by
+// action type ("click", "type", ...) with "*" as the default for other types
+func WithActionDelays(delays map[string]time.Duration) Option {
+	return func(c *config) {
+		c.actionDelays = delays
+	}
+}
+
+// WithScreenshotReuse sends the previous screenshot again instead of taking a
+// new one after the given action types, e.g. "move" or "wait", saving the
+// capture for actions that do not change the screen. Other action types and
+// failed actions always get a fresh screenshot.
+func WithScreenshotReuse(actionTypes ...string) Option {
+	return func(c *config) {
+		if c.reuseScreenshot == nil {
+			c.reuseScreenshot = make(map[string]bool)
+		}
+		for _, t := range actionTypes {
+			if !slices.Contains(ActionTypes, t) {
+				c.errs = append(c.errs, fmt.Errorf("WithScreenshotReuse: unknown action type %q", t))
+				continue
+			}
+			c.reuseScreenshot[t] = true
+		}
+	}
+}
+
+// WithScreenshotPipeline sets how screenshots are encoded for the model, e.g.
+// as JPEG, and whether unchanged screens are skipped, to cut request size and
+// input tokens
+func WithScreenshotPipeline(p ScreenshotPipeline) Option {
+	return func(c *config) {
+		if p.JPEGQuality < 0 || p.JPEGQuality > 100 {
+			c.errs = append(c.errs, fmt.Errorf("WithScreenshotPipeline: JPEG quality %d is not between 1 and 100", p.JPEGQuality))
+			return
+		}
+		if p.Threshold < 0 || p.Threshold >= 1 {
+			c.errs = append(c.errs, fmt.Errorf("WithScreenshotPipeline: threshold %v is not between 0 and 1", p.Threshold))
+			return
+		}
+		c.screenshots = &p
+	}
+}
+
+// WithHumanPacing moves the mouse along curved paths and types with a human-like
+// cadence on computers that support it, such as Browser
+func WithHumanPacing() Option {
+	return func(c *config) {
+		c.humanPacing = true
+	}
+}
+
+// WithViewportInfo tells the model the scroll offset and total page size after
+// every action, so it knows how much of the page remains below the fold
+func WithViewportInfo() Option {
+	return func(c *config) {
+		c.viewportInfo = true
+	}
+}
+
+// WithPageMetadata tells the model the page title, the HTTP status of the last
+// navigation and the load state after every action
+func WithPageMetadata() Option {
+	return func(c *config) {
+		c.pageMetadata = true
+	}
+}
+
+// WithPageContext sends the page title with its text and/or an accessibility
+// summary, as selected by pc, to the model after every browser action
+func WithPageContext(pc PageContext) Option {
+	return func(c *config) {
+		if !pc.Text && !pc.Accessibility {
+			c.errs = append(c.errs, fmt.Errorf("WithPageContext: neither text nor accessibility is selected"))
+			return
+		}
+		c.pageContext = &pc
+	}
+}
+
+// WithMemory offers the model memory_write and memory_read tools backed by m,
+// which the caller can pre-fill and inspect after the run. A nil m starts empty.
+func WithMemory(m *Memory) Option {
+	return func(c *config) {
+		// A nil m gives every config built from the option its own memory
+		mem := m
+		if mem == nil {
+			mem = NewMemory()
+		}
+		c.memory = mem
+		c.tools = append(c.tools, memoryTools(mem)...)
+	}
+}
+
+// WithTaskRetry restarts a failed run (max turns reached, answer validation
+// failed, navigation loop, or rejection by critic) up to retries times with a
+// prompt summarizing what went wrong. critic may be nil; when set, a non-nil
+// error from it rejects the final answer.
+func WithTaskRetry(retries int, critic func(answer string) error) Option {
+	return func(c *config) {
+		c.taskRetries = retries
+		c.critic = critic
+	}
+}
+
+// withReset sets how the computer is brought back to its starting state before a retry
+func withReset(reset func(ctx context.Context) error) Option {
+	return func(c *config) {
+		c.reset = reset
+	}
+}
+
+// WithResilience sets the API error policy and retries transient errors
+// (network, 429, 5xx) up to retries times. Strict (default) then aborts the
+// run. Resilient also saves a Checkpoint to checkpointPath so the session can
+// be resumed later with WithCheckpoint.
+func WithResilience(mode Resilience, retries int, checkpointPath string) Option {
+	return func(c *config) {
+		c.resilience = mode
+		c.retryPolicy.MaxRetries = retries
+		c.checkpointPath = checkpointPath
+	}
+}
+
+// WithNavigationPolicy checks the URL after every action against policy and
+// navigates back or aborts the run when a blocked site is reached
+func WithNavigationPolicy(policy NavigationPolicy) Option {
+	return func(c *config) {
+		switch policy.OnBlocked {
+		case "":
+			policy.OnBlocked = NavigateBack
+		case NavigateBack, AbortNavigation:
+		default:
+			c.errs = append(c.errs, fmt.Errorf("WithNavigationPolicy: unknown OnBlocked %q", policy.OnBlocked))
+			return
+		}
+		c.navigationPolicy = &policy
+	}
+}
+
+// WithTurnBudget tells the model after every turn how many turns it has left.
+// With wrapUp it is asked on the last turn to stop and answer, so runs end
+// with an answer instead of running out of turns mid-task.
+func WithTurnBudget(wrapUp bool) Option {
+	return func(c *config) {
+		c.turnBudget = true
+		c.wrapUp = wrapUp
+	}
+}
+
+// WithBudget stops the run with a *BudgetExceededError, matching
+// ErrBudgetExceeded, once it uses more tokens, money or time than budget allows
+func WithBudget(budget Budget) Option {
+	return func(c *config) {
+		c.budget = &budget
+	}
+}
+
+// WithTraceRecorder writes a replayable trace of the run with r; see ReplayTrace
+func WithTraceRecorder(r *TraceRecorder) Option {
+	return func(c *config) {
+		c.trace = r
+	}
+}
+
+// WithObserver mirrors the run's actions, frames and URL changes to the
+// subscribers of o
+func WithObserver(o *Observer) Option {
+	return func(c *config) {
+		c.observer = o
+	}
+}
+
+// WithSessionFile saves the Session to path after every turn, so the run can
+// be continued with ResumeBrowserUse if the process dies
+func WithSessionFile(path string) Option {
+	return func(c *config) {
+		c.sessionFile = path
+	}
+}
+
+// WithCheckpoint resumes the conversation saved in a checkpoint instead of
+// sending the instruction as a new task
+func WithCheckpoint(cp *Checkpoint) Option {
+	return func(c *config) {
+		c.resume = cp
+	}
+}
+
+// WithArtifacts saves screenshots and manifest.json under dir/sessionID
+// (default "screenshots/<generated id>"). An empty sessionID is generated.
+func WithArtifacts(dir, sessionID string) Option {
+	return func(c *config) {
+		if dir != "" {
+			c.artifactsDir = dir
+		}
+		c.sessionID = sessionID
+	}
+}
+
+// WithApproval routes the actions selected by policy through approve before
+// they are executed. Denied actions are skipped and the model is told why, so
+// it can replan instead of the run failing.
+func WithApproval(approve ApprovalFunc, policy ApprovalPolicy) Option {
+	return func(c *config) {
+		c.approvalFunc = approve
+		c.approvalPolicy = policy
+	}
+}
+
+// WithSafetyCheckHandler lets handler acknowledge or reject pending safety checks.
+// Without a handler every check is rejected and the run stops with a
+// *SafetyCheckError; pass AutoAcknowledge to acknowledge all checks.
+func WithSafetyCheckHandler(handler SafetyCheckHandler) Option {
+	return func(c *config) {
+		c.safetyHandler = handler
+	}
+}
+
+// WithEvents replaces the default console output with callbacks notified about
+// the progress of the run. Extend ConsoleEvents to keep the console output, or
+// pass nil to run silently.
+func WithEvents(events *Events) Option {
+	return func(c *config) {
+		c.events = events
+	}
+}
+
+// WithCredentials offers the model a fill_credential tool that types the
+// credential matching the current page's domain into the focused field. Each
+// credential is released only on https pages of its own domain or subdomains.
+func WithCredentials(creds ...Credential) Option {
+	return func(c *config) {
+		for _, cred := range creds {
+			if err := validScope(cred.Domain); err != nil {
+				c.errs = append(c.errs, fmt.Errorf("credential: %w", err))
+				return
+			}
+		}
+		c.tools = append(c.tools, credentialTool(creds))
+	}
+}
+
+// WithCookies adds cookies to the browser before the first page is opened.
+// Every cookie must name its domain; cookies are never sent to other sites.
+func WithCookies(cookies ...Cookie) Option {
+	return func(c *config) {
+		c.cookies = append(c.cookies, cookies...)
+	}
+}
+
+// WithDownloads saves files downloaded during a browser run into dir and adds
+// them to Result.Downloads. parsers, keyed by file extension (e.g. "csv"),
+// extract data from the files into the result; see ParseCSV.
+func WithDownloads(dir string, parsers map[string]DownloadParser) Option {
+	return func(c *config) {
+		c.downloadDir = dir
+		c.downloadParsers = parsers
+	}
+}
+
+// WithUploadFiles offers the files at paths for upload. File choosers opened
+// by the page are intercepted, and the model attaches a file by its base name
+// with the attach_file tool.
+func WithUploadFiles(paths ...string) Option {
+	return func(c *config) {
+		if len(paths) == 0 {
+			return
+		}
+		c.uploadFiles = uploadFileNames(paths)
+		c.tools = append(c.tools, attachFileTool(c.uploadFiles))
+	}
+}
+
+// WithAPITimeouts sets the connect, TLS, response-header and total timeouts of
+// OpenAI API calls, overriding the OPENAI_*_TIMEOUT environment variables
+func WithAPITimeouts(t Timeouts) Option {
+	return func(c *config) {
+		c.timeouts = &t
+	}
+}
+
+// WithRateLimitPause pauses the session when the rate limit is exhausted and
+// resumes it automatically once the reset window from the response headers
+// has passed, as long as the wait is no longer than maxWait
+func WithRateLimitPause(maxWait time.Duration) Option {
+	return func(c *config) {
+		c.rateLimitWait = maxWait
+	}
+}
+
+// WithReasoningSummary asks the model for a summary of its reasoning at the
+// given level ("auto", "concise" or "detailed"). Summaries are recorded per
+// turn in manifest.json and report.html next to the screenshots.
+func WithReasoningSummary(level string) Option {
+	return func(c *config) {
+		c.reasoningSummary = level
+	}
+}
+
+// WithPrivacyZones blacks out the elements matching the CSS selectors (e.g.
+// ".account-balance", "#ssn-field") in every browser screenshot before it is
+// saved or sent to the model
+func WithPrivacyZones(selectors ...string) Option {
+	return func(c *config) {
+		c.privacySelectors = append(c.privacySelectors, selectors...)
+	}
+}
+
+// WithSetupActions runs a hand-written sequence of actions (see RunActions)
+// before the model takes over, and again before every retry
+func WithSetupActions(actions ...Action) Option {
+	return func(c *config) {
+		c.setupActions = append(c.setupActions, actions...)
+	}
+}
+
+// WithAnswerVerification sends the final screenshot and answer of a completed
+// run to a cheaper judge model with a verification rubric and records the
+// verdict in Result.Verification. An empty rubric uses DefaultVerificationRubric.
+func WithAnswerVerification(model, rubric string) Option {
+	return func(c *config) {
+		if rubric == "" {
+			rubric = DefaultVerificationRubric
+		}
+		c.verifyModel = model
+		c.verifyRubric = rubric
+	}
+}
+
+// WithKeepProfile leaves the temporary browser profile of BrowserUse on disk
+// after the session instead of removing it
+func WithKeepProfile() Option {
+	return func(c *config) {
+		c.keepProfile = true
+	}
+}
+
+// WithStreaming streams every response and calls onEvent for each server-sent
+// event as it arrives, e.g. to display reasoning and assistant text live
+func WithStreaming(onEvent func(StreamEvent)) Option {
+	return func(c *config) {
+		c.onStream = onEvent
+	}
+}
+
+// WithTags attaches labels such as team, ticket ID or environment to the
+// session. They are sent as response metadata and saved in the result, the
+// checkpoint and manifest.json, where FindSessions can search for them.
+func WithTags(tags map[string]string) Option {
+	return func(c *config) {
+		if c.tags == nil {
+			c.tags = map[string]string{}
+		}
+		for k, v := range tags {
+			c.tags[k] = v
+		}
+		if len(c.tags) > maxTags {
+			c.errs = append(c.errs, fmt.Errorf("at most %d tags are supported, got %d", maxTags, len(c.tags)))
+		}
+	}
+}
+
+// WithRetryPolicy sets how transient API errors are retried (default DefaultRetryPolicy)
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *config) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithSaveState snapshots the page state (URL, cookies, storage and scroll
+// position) of a browser run to path when the run ends; see WithWarmStart
+func WithSaveState(path string) Option {
+	return func(c *config) {
+		c.saveStatePath = path
+	}
+}
+
+// WithWarmStart restores a page state saved by an earlier run before the
+// model takes over, instead of starting from a fresh page
+func WithWarmStart(state *PageState) Option {
+	return func(c *config) {
+		c.warmStart = state
+	}
+}
+
+// WithCookieExport saves the browser's cookies to path when a browser run
+// ends, so a later session can reuse the logged-in state with LoadCookies and
+// WithCookies
+func WithCookieExport(path string) Option {
+	return func(c *config) {
+		c.cookieExportPath = path
+	}
+}
+
+// WithUserAgent sets the User-Agent header and navigator.userAgent of the browser pages
+func WithUserAgent(userAgent string) Option {
+	return func(c *config) {
+		c.emulation.userAgent = userAgent
+	}
+}
+
+// WithLocale sets the Accept-Language header, navigator.language and the
+// JavaScript locale of the browser pages, e.g. "ja-JP"
+func WithLocale(locale string) Option {
+	return func(c *config) {
+		c.emulation.locale = locale
+	}
+}
+
+// WithTimezone sets the timezone of the browser pages, e.g. "Asia/Tokyo"
+func WithTimezone(timezone string) Option {
+	return func(c *config) {
+		c.emulation.timezone = timezone
+	}
+}
+
+// WithLocalStorage preloads localStorage entries for origin, e.g.
+// "https://example.com", before the pages of that origin run their scripts.
+// Entries the site has already set are left as they are.
+func WithLocalStorage(origin string, items map[string]string) Option {
+	return func(c *config) {
+		u, err := url.Parse(origin)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			c.errs = append(c.errs, fmt.Errorf("WithLocalStorage: invalid origin %q", origin))
+			return
+		}
+		origin = u.Scheme + "://" + u.Host
+		if c.emulation.localStorage == nil {
+			c.emulation.localStorage = make(map[string]map[string]string)
+		}
+		if c.emulation.localStorage[origin] == nil {
+			c.emulation.localStorage[origin] = make(map[string]string)
+		}
+		for k, v := range items {
+			c.emulation.localStorage[origin][k] = v
+		}
+	}
+}
+
+// WithRemoteBrowser attaches to a running browser instead of launching one,
+// through a DevTools WebSocket URL (e.g. browserless or a Docker container) or
+// the host:port of a Chrome started with --remote-debugging-port. The browser
+// keeps running after the session; only the page opened for it is closed.
+func WithRemoteBrowser(url string) Option {
+	return func(c *config) {
+		c.remoteURL = url
+	}
+}
+
+// WithHeadless chooses between a headless (default) and a visible browser
+func WithHeadless(headless bool) Option {
+	return func(c *config) {
+		c.headless = headless
+	}
+}
+
+// WithUserDataDir launches the browser with a persistent profile in dir, e.g.
+// to reuse logged-in sessions. The profile is kept when the session ends.
+func WithUserDataDir(dir string) Option {
+	return func(c *config) {
+		c.userDataDir = dir
+	}
+}
+
+// WithProxy sends the browser's traffic through an HTTP or SOCKS proxy, e.g.
+// "http://proxy:3128" or "socks5://localhost:1080"
+func WithProxy(proxy string) Option {
+	return func(c *config) {
+		c.proxy = proxy
+	}
+}
+
+// WithNoSandbox disables the Chrome sandbox, which is required when running as root in containers
+func WithNoSandbox() Option {
+	return func(c *config) {
+		c.noSandbox = true
+	}
+}
+
+// WithLaunchFlags adds Chrome command-line flags such as "lang=ja" or
+// "disable-gpu" to the launched browser
+func WithLaunchFlags(flags ...string) Option {
+	return func(c *config) {
+		c.launchFlags = append(c.launchFlags, flags...)
+	}
+}
+
+// WithMaxScreenshotSize downscales screenshots of larger displays to fit
+// width x height, keeping the aspect ratio. The computer tool declares the
+// downscaled size and the model's coordinates are mapped back to the screen.
+// By default screenshots are fitted to the model's optimal display size.
+func WithMaxScreenshotSize(width, height int) Option {
+	return func(c *config) {
+		if width <= 0 || height <= 0 {
+			c.errs = append(c.errs, fmt.Errorf("WithMaxScreenshotSize: invalid size %dx%d", width, height))
+			return
+		}
+		c.maxScreenshotWidth, c.maxScreenshotHeight = width, height
+	}
+}
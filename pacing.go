@@ -0,0 +1,58 @@
+package computeruse
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// SetHumanPacing makes the browser move the mouse along curved paths and type
+// with a human-like cadence instead of teleporting the pointer and inserting text at once
+func (b *Browser) SetHumanPacing(enabled bool) {
+	b.human = enabled
+}
+
+// moveMouse moves the mouse to x, y, along a human-like curve when pacing is enabled
+func (b *Browser) moveMouse(page *rod.Page, x, y int) error {
+	to := proto.Point{X: float64(x), Y: float64(y)}
+	if !b.human {
+		return page.Mouse.MoveTo(to)
+	}
+
+	// Cubic Bézier curve with control points jittered off the straight line
+	from := page.Mouse.Position()
+	jitter := func() float64 { return (rand.Float64() - 0.5) * 200 }
+	c1 := proto.Point{X: from.X + (to.X-from.X)/3 + jitter(), Y: from.Y + (to.Y-from.Y)/3 + jitter()}
+	c2 := proto.Point{X: from.X + 2*(to.X-from.X)/3 + jitter(), Y: from.Y + 2*(to.Y-from.Y)/3 + jitter()}
+
+	steps := 20 + rand.IntN(20)
+	for i := 1; i <= steps; i++ {
+		t := float64(i) / float64(steps)
+		u := 1 - t
+		p := proto.Point{
+			X: u*u*u*from.X + 3*u*u*t*c1.X + 3*u*t*t*c2.X + t*t*t*to.X,
+			Y: u*u*u*from.Y + 3*u*u*t*c1.Y + 3*u*t*t*c2.Y + t*t*t*to.Y,
+		}
+		if err := page.Mouse.MoveTo(p); err != nil {
+			return err
+		}
+		time.Sleep(time.Duration(5+rand.IntN(10)) * time.Millisecond)
+	}
+	return nil
+}
+
+// typeText inserts text, one character at a time with a human-like cadence when pacing is enabled
+func (b *Browser) typeText(page *rod.Page, text string) error {
+	if !b.human {
+		return page.InsertText(text)
+	}
+	for _, r := range text {
+		if err := page.InsertText(string(r)); err != nil {
+			return err
+		}
+		time.Sleep(time.Duration(40+rand.IntN(120)) * time.Millisecond)
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// PageContext selects the page text sent to the model next to each browser
+// screenshot. Long articles and tables are read far more reliably as text.
+type PageContext struct {
+	// Text adds the rendered text of the page
+	Text bool
+	// Accessibility adds the headings, links and controls of the accessibility tree
+	Accessibility bool
+	// MaxChars limits each part to this many bytes of UTF-8 text, 4000 by default
+	MaxChars int
+}
+
+// PageContent is the text of a page extracted for the model
+type PageContent struct {
+	Title         string `json:"title"`
+	Text          string `json:"text,omitempty"`
+	Accessibility string `json:"accessibility,omitempty"`
+}
+
+// pageTextJS returns the title and rendered text of the page with the text of
+// the elements matching the privacy selectors redacted, and the redacted texts
+const pageTextJS = `(selectors) => {
+	let title = document.title;
+	let text = document.body ? document.body.innerText : "";
+	const redacted = [];
+	for (const selector of selectors) {
+		for (const el of document.querySelectorAll(selector)) {
+			const t = el.innerText.trim();
+			if (!t) continue;
+			redacted.push(t);
+			title = title.split(t).join("[redacted]");
+			text = text.split(t).join("[redacted]");
+		}
+	}
+	return {title, text: text.replace(/\n\s*\n+/g, "\n").trim(), redacted};
+}`
+
+// axRoles are the accessibility roles summarized for the model
+var axRoles = map[string]bool{
+	"heading": true, "link": true, "button": true, "textbox": true, "searchbox": true,
+	"combobox": true, "checkbox": true, "radio": true, "switch": true, "slider": true,
+	"tab": true, "menuitem": true, "option": true, "img": true, "dialog": true, "alert": true,
+}
+
+// PageContent extracts the title and, as selected by pc, the text and an
+// accessibility summary of the current page. The text of elements matching
+// the privacy selectors is redacted everywhere, and the accessibility nodes
+// within those elements are left out.
+func (b *Browser) PageContent(ctx context.Context, pc PageContext, privacySelectors []string) (*PageContent, error) {
+	limit := pc.MaxChars
+	if limit <= 0 {
+		limit = 4000
+	}
+	if privacySelectors == nil {
+		privacySelectors = []string{}
+	}
+	var content PageContent
+	err := b.do(ctx, func(page *rod.Page) error {
+		obj, err := page.Eval(pageTextJS, privacySelectors)
+		if err != nil {
+			return err
+		}
+		var text struct {
+			Title    string   `json:"title"`
+			Text     string   `json:"text"`
+			Redacted []string `json:"redacted"`
+		}
+		if err := obj.Value.Unmarshal(&text); err != nil {
+			return err
+		}
+		content.Title = text.Title
+		if pc.Text {
+			content.Text = truncateText(text.Text, limit)
+		}
+		if pc.Accessibility {
+			private, err := privateNodes(page, privacySelectors)
+			if err != nil {
+				return err
+			}
+			summary, err := accessibilitySummary(page, private)
+			if err != nil {
+				return err
+			}
+			content.Accessibility = truncateText(redactText(summary, text.Redacted), limit)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading page content: %w", err)
+	}
+	return &content, nil
+}
+
+// privateNodes returns the backend IDs of the DOM nodes within the elements
+// matching the privacy selectors
+func privateNodes(page *rod.Page, selectors []string) (map[proto.DOMBackendNodeID]bool, error) {
+	private := map[proto.DOMBackendNodeID]bool{}
+	var collect func(n *proto.DOMNode)
+	collect = func(n *proto.DOMNode) {
+		private[n.BackendNodeID] = true
+		for _, child := range n.Children {
+			collect(child)
+		}
+		for _, child := range n.ShadowRoots {
+			collect(child)
+		}
+	}
+	for _, selector := range selectors {
+		elements, err := page.Elements(selector)
+		if err != nil {
+			return nil, fmt.Errorf("error finding private elements %s: %w", selector, err)
+		}
+		for _, el := range elements {
+			depth := -1
+			node, err := proto.DOMDescribeNode{ObjectID: el.Object.ObjectID, Depth: &depth, Pierce: true}.Call(page)
+			if err != nil {
+				return nil, fmt.Errorf("error describing private element %s: %w", selector, err)
+			}
+			collect(node.Node)
+		}
+	}
+	return private, nil
+}
+
+// redactText replaces every occurrence of the private texts
+func redactText(text string, private []string) string {
+	for _, p := range private {
+		text = strings.ReplaceAll(text, p, "[redacted]")
+	}
+	return text
+}
+
+// accessibilitySummary lists the named headings, links and controls of the
+// page, one "role: name" per line, leaving out the nodes in private
+func accessibilitySummary(page *rod.Page, private map[proto.DOMBackendNodeID]bool) (string, error) {
+	tree, err := proto.AccessibilityGetFullAXTree{}.Call(page)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, node := range tree.Nodes {
+		if node.Ignored || node.Role == nil || node.Name == nil || private[node.BackendDOMNodeID] {
+			continue
+		}
+		role, name := node.Role.Value.Str(), strings.TrimSpace(node.Name.Value.Str())
+		if !axRoles[role] || name == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s: %s\n", role, name)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// truncateText cuts text to limit bytes on a rune boundary and says so
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut] + fmt.Sprintf("\n[truncated, %d more bytes]", len(text)-cut)
+}
+
+// pageContentMessage gives the model the extracted text of the page
+func pageContentMessage(c *PageContent) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Page content of %q:", c.Title)
+	if c.Text != "" {
+		sb.WriteString("\n\nText:\n" + c.Text)
+	}
+	if c.Accessibility != "" {
+		sb.WriteString("\n\nAccessibility tree:\n" + c.Accessibility)
+	}
+	return sb.String()
+}
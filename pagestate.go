@@ -0,0 +1,114 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-rod/rod"
+)
+
+// PageState is a snapshot of a page that a later session can start from,
+// e.g. to resume a half-filled application on another day
+type PageState struct {
+	URL            string            `json:"url"`
+	Cookies        []Cookie          `json:"cookies,omitempty"`
+	LocalStorage   map[string]string `json:"local_storage,omitempty"`
+	SessionStorage map[string]string `json:"session_storage,omitempty"`
+	ScrollX        int               `json:"scroll_x"`
+	ScrollY        int               `json:"scroll_y"`
+}
+
+// storageStateJS reads the storage and scroll position of the current page
+const storageStateJS = `() => {
+	const dump = (s) => { const o = {}; for (let i = 0; i < s.length; i++) { const k = s.key(i); o[k] = s.getItem(k); } return o; };
+	return {
+		local_storage: dump(localStorage), session_storage: dump(sessionStorage),
+		scroll_x: Math.round(window.scrollX), scroll_y: Math.round(window.scrollY),
+	};
+}`
+
+// restoreStorageJS writes storage entries into the current page
+const restoreStorageJS = `(local, session) => {
+	for (const [k, v] of Object.entries(local || {})) localStorage.setItem(k, v);
+	for (const [k, v] of Object.entries(session || {})) sessionStorage.setItem(k, v);
+}`
+
+// CaptureState snapshots the URL, cookies, storage and scroll position of the current page
+func (b *Browser) CaptureState(ctx context.Context) (*PageState, error) {
+	state := &PageState{URL: b.GetCurrentUrl()}
+	err := b.do(ctx, func(page *rod.Page) error {
+		obj, err := page.Eval(storageStateJS)
+		if err != nil {
+			return err
+		}
+		return obj.Value.Unmarshal(state)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error capturing page state: %w", err)
+	}
+	if state.Cookies, err = b.Cookies(ctx); err != nil {
+		return nil, fmt.Errorf("error capturing page state: %w", err)
+	}
+	return state, nil
+}
+
+// RestoreState loads a snapshot taken by CaptureState: it sets the cookies,
+// opens the URL, refills the storage and scrolls back to the saved position
+func (b *Browser) RestoreState(ctx context.Context, state *PageState) error {
+	if len(state.Cookies) > 0 {
+		if err := b.SetCookies(ctx, state.Cookies); err != nil {
+			return err
+		}
+	}
+	if err := b.Navigate(ctx, state.URL); err != nil {
+		return err
+	}
+	err := b.do(ctx, func(page *rod.Page) error {
+		if len(state.LocalStorage) > 0 || len(state.SessionStorage) > 0 {
+			if _, err := page.Eval(restoreStorageJS, state.LocalStorage, state.SessionStorage); err != nil {
+				return err
+			}
+			// Reload so the page picks up the restored storage
+			if err := page.Reload(); err != nil {
+				return err
+			}
+			if err := settle(page, time.Second); err != nil {
+				return err
+			}
+		}
+		_, err := page.Eval(`(x, y) => window.scrollTo(x, y)`, state.ScrollX, state.ScrollY)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("error restoring page state: %w", err)
+	}
+	return nil
+}
+
+// SavePageState writes a page state as JSON. The file holds session cookies, so it is private to the user.
+func SavePageState(path string, state *PageState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding page state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error saving page state: %w", err)
+	}
+	return nil
+}
+
+// LoadPageState reads a page state saved by SavePageState
+func LoadPageState(path string) (*PageState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading page state: %w", err)
+	}
+	var state PageState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error decoding page state: %w", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,128 @@
+package computeruse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// ParityStep compares the outcome of one action in headless and headful browsers
+type ParityStep struct {
+	Action        Action  `json:"action"`
+	HeadlessURL   string  `json:"headless_url"`
+	HeadfulURL    string  `json:"headful_url"`
+	HeadlessError string  `json:"headless_error,omitempty"`
+	HeadfulError  string  `json:"headful_error,omitempty"`
+	PixelDiff     float64 `json:"pixel_diff"`
+	Mismatch      bool    `json:"mismatch"`
+}
+
+// ParityReport is the result of ParityTest
+type ParityReport struct {
+	Steps      []ParityStep `json:"steps"`
+	Mismatches int          `json:"mismatches"`
+}
+
+// ParityTest replays the same actions in a headless and a headful browser and
+// diffs the screenshots, URLs and errors after each step, to diagnose tasks
+// that work headful but fail headless. A step is a mismatch when more than
+// threshold (0-1) of the pixels differ, or the URLs or errors differ. Both
+// browsers are launched with opts, such as WithDisplaySize or WithProxy.
+func ParityTest(ctx context.Context, url string, actions []Action, threshold float64, opts ...Option) (*ParityReport, error) {
+	opts = opts[:len(opts):len(opts)]
+	headless, err := LaunchBrowser(ctx, append(opts, WithHeadless(true))...)
+	if err != nil {
+		return nil, err
+	}
+	defer headless.Close()
+	headful, err := LaunchBrowser(ctx, append(opts, WithHeadless(false))...)
+	if err != nil {
+		return nil, err
+	}
+	defer headful.Close()
+
+	for _, b := range []*Browser{headless, headful} {
+		if err := b.Open(ctx, url); err != nil {
+			return nil, fmt.Errorf("error opening browser: %w", err)
+		}
+	}
+
+	report := &ParityReport{}
+	for _, action := range actions {
+		step := ParityStep{Action: action}
+		var shots [2][]byte
+		for i, b := range []*Browser{headless, headful} {
+			errText := ""
+			if err := performAction(ctx, b, &action); err != nil {
+				errText = err.Error()
+			}
+			shot, err := b.Screenshot(ctx)
+			if err != nil {
+				return report, err
+			}
+			shots[i] = shot
+			if i == 0 {
+				step.HeadlessURL, step.HeadlessError = b.GetCurrentUrl(), errText
+			} else {
+				step.HeadfulURL, step.HeadfulError = b.GetCurrentUrl(), errText
+			}
+		}
+
+		diff, err := pixelDiff(shots[0], shots[1])
+		if err != nil {
+			return report, err
+		}
+		step.PixelDiff = diff
+		step.Mismatch = diff > threshold || step.HeadlessURL != step.HeadfulURL || step.HeadlessError != step.HeadfulError
+		if step.Mismatch {
+			report.Mismatches++
+		}
+		report.Steps = append(report.Steps, step)
+	}
+	return report, nil
+}
+
+// pixelDiff returns the fraction of pixels that differ noticeably between two PNG screenshots
+func pixelDiff(a, b []byte) (float64, error) {
+	imgA, err := png.Decode(bytes.NewReader(a))
+	if err != nil {
+		return 0, fmt.Errorf("error decoding screenshot: %w", err)
+	}
+	imgB, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		return 0, fmt.Errorf("error decoding screenshot: %w", err)
+	}
+	if imgA.Bounds() != imgB.Bounds() {
+		return 1, nil
+	}
+	return diffImages(imgA, imgB), nil
+}
+
+// diffImages returns the fraction of pixels whose channels differ by more than a small tolerance
+func diffImages(a, b image.Image) float64 {
+	bounds := a.Bounds()
+	total, different := 0, 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			total++
+			r1, g1, b1, _ := a.At(x, y).RGBA()
+			r2, g2, b2, _ := b.At(x, y).RGBA()
+			if absDiff(r1, r2) > 0x1000 || absDiff(g1, g2) > 0x1000 || absDiff(b1, b2) > 0x1000 {
+				different++
+			}
+		}
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(different) / float64(total)
+}
+
+func absDiff(a, b uint32) uint32 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
@@ -0,0 +1,101 @@
+package computeruse
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// ScreenshotPipeline configures how screenshots are encoded before they are
+// sent to the model. Screenshots are already downscaled to the declared display
+// size, see WithMaxScreenshotSize; traces, artifacts and observers keep the PNG.
+type ScreenshotPipeline struct {
+	// JPEGQuality encodes screenshots as JPEG at this quality, from 1 to 100.
+	// Zero keeps PNG.
+	JPEGQuality int
+	// SkipUnchanged sends a small placeholder image and a "no visual change"
+	// note instead of a screenshot that matches the previous one
+	SkipUnchanged bool
+	// Threshold is the fraction of pixels that may differ noticeably for a
+	// screenshot to still count as unchanged. Zero requires every pixel to match.
+	Threshold float64
+}
+
+// noChangeMessage replaces the screenshot of an action that changed nothing
+const noChangeMessage = "No visual change: the screen looks the same as in the previous screenshot, " +
+	"so a blank placeholder was sent instead. Rely on the previous screenshot."
+
+// placeholderImage is the data URL of the image sent for an unchanged screen
+var placeholderImage = func() string {
+	img := image.NewGray(image.Rect(0, 0, 8, 8))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+	return dataURL(buf.Bytes())
+}()
+
+// prepare encodes the screenshot of out for the model. previous is the frame
+// of the preceding action; when the screen did not change the screenshot is
+// replaced with a placeholder and prepare reports true.
+func (p *ScreenshotPipeline) prepare(out *ComputerOutput, previous string) (bool, error) {
+	if p == nil || out.ImageURL == "" {
+		return false, nil
+	}
+	if p.SkipUnchanged && previous != "" && p.unchanged(out.ImageURL, previous) {
+		out.ImageURL = placeholderImage
+		return true, nil
+	}
+	if p.JPEGQuality > 0 {
+		data, err := decodeDataURL(out.ImageURL)
+		if err != nil {
+			return false, err
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return false, fmt.Errorf("error decoding screenshot: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: p.JPEGQuality}); err != nil {
+			return false, fmt.Errorf("error encoding screenshot: %w", err)
+		}
+		out.ImageURL = "data:image/jpeg;base64," + base64.StdEncoding.EncodeToString(buf.Bytes())
+	}
+	return false, nil
+}
+
+// unchanged compares two PNG frames, first byte for byte and then pixel by pixel
+func (p *ScreenshotPipeline) unchanged(current, previous string) bool {
+	if current == previous {
+		return true
+	}
+	a, err := decodeDataURL(current)
+	if err != nil {
+		return false
+	}
+	b, err := decodeDataURL(previous)
+	if err != nil {
+		return false
+	}
+	diff, err := pixelDiff(a, b)
+	if err != nil {
+		return false
+	}
+	if p.Threshold == 0 {
+		return diff == 0
+	}
+	return diff <= p.Threshold
+}
+
+// imageFileName names an uploaded screenshot after its encoding
+func imageFileName(data []byte) string {
+	if http.DetectContentType(data) == "image/jpeg" {
+		return "screenshot.jpg"
+	}
+	return "screenshot.png"
+}
@@ -0,0 +1,197 @@
+package computeruse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PoolTask is a browser task run by a Pool
+type PoolTask struct {
+	URL         string `json:"url"`
+	Instruction string `json:"instruction"`
+	// MaxTurns overrides the pool's limit when set
+	MaxTurns int `json:"max_turns,omitempty"`
+	// Options apply to this task on top of the pool's
+	Options []Option `json:"-"`
+}
+
+// PoolResult is the outcome of a PoolTask. Result is nil when the task could
+// not start, e.g. because the browser failed to launch.
+type PoolResult struct {
+	Task   PoolTask `json:"task"`
+	Result *Result  `json:"result,omitempty"`
+	Err    error    `json:"-"`
+}
+
+// Pool runs many browser tasks concurrently, each in its own browser page
+// with its own response chain
+type Pool struct {
+	// Concurrency is the number of tasks running at once
+	Concurrency int
+	// MaxTurns limits the tasks that do not set their own
+	MaxTurns int
+	// TaskTimeout bounds each task; zero bounds tasks by the context of RunAll only
+	TaskTimeout time.Duration
+	// Shared runs the tasks in isolated incognito contexts of one browser
+	// instead of launching a browser per task
+	Shared bool
+	opts   []Option
+}
+
+// NewPool creates a pool running up to concurrency tasks at once with the
+// given options, such as WithModel or WithDisplaySize
+func NewPool(concurrency, maxTurns int, opts ...Option) *Pool {
+	return &Pool{Concurrency: concurrency, MaxTurns: maxTurns, opts: opts}
+}
+
+// NewPool creates a pool running tasks with the client's options
+func (c *Client) NewPool(concurrency, maxTurns int, opts ...Option) *Pool {
+	return NewPool(concurrency, maxTurns, c.options(opts)...)
+}
+
+// RunAll runs tasks with a new pool; see Pool.RunAll
+func RunAll(ctx context.Context, tasks []PoolTask, concurrency, maxTurns int, opts ...Option) []PoolResult {
+	return NewPool(concurrency, maxTurns, opts...).RunAll(ctx, tasks)
+}
+
+// RunAll runs the tasks and returns their outcomes in the order of tasks.
+// A failing task does not stop the others; canceling ctx stops them all.
+// Each task gets its own copy of the memory and its own numbered session,
+// checkpoint, cookie and state files and download directory, e.g. session-2.json
+// for the second task.
+func (p *Pool) RunAll(ctx context.Context, tasks []PoolTask) []PoolResult {
+	results := make([]PoolResult, len(tasks))
+	for i, task := range tasks {
+		results[i].Task = task
+	}
+	fail := func(err error) []PoolResult {
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	cfg := newConfig(p.opts)
+	if err := errors.Join(cfg.errs...); err != nil {
+		return fail(err)
+	}
+	var shared *Browser
+	if p.Shared {
+		var err error
+		if shared, err = LaunchBrowser(ctx, p.opts...); err != nil {
+			return fail(err)
+		}
+		defer shared.Close()
+	}
+
+	sem := make(chan struct{}, max(1, p.Concurrency))
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i].Err = ctx.Err()
+				return
+			}
+			results[i].Result, results[i].Err = p.run(ctx, i, task, shared)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// run executes a task in its own browser, or in an incognito context of shared
+func (p *Pool) run(ctx context.Context, i int, task PoolTask, shared *Browser) (*Result, error) {
+	if p.TaskTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.TaskTimeout)
+		defer cancel()
+	}
+	maxTurns := task.MaxTurns
+	if maxTurns == 0 {
+		maxTurns = p.MaxTurns
+	}
+	opts := append(p.opts[:len(p.opts):len(p.opts)], isolateSession(i))
+	opts = append(opts, task.Options...)
+	if shared == nil {
+		return BrowserUseResult(ctx, task.URL, task.Instruction, maxTurns, opts...)
+	}
+
+	taskCfg := newConfig(opts)
+	if err := errors.Join(taskCfg.errs...); err != nil {
+		return nil, err
+	}
+	browser, err := shared.incognito()
+	if err != nil {
+		return nil, err
+	}
+	return runBrowser(ctx, browser, taskCfg, task.URL, task.Instruction, maxTurns, opts)
+}
+
+// incognito returns a browser sharing the process of b with its own cookies,
+// storage and cache. Closing it discards that state and leaves b running.
+func (b *Browser) incognito() (*Browser, error) {
+	incognito, err := b.browser.Incognito()
+	if err != nil {
+		return nil, fmt.Errorf("error creating incognito context: %w", err)
+	}
+	return &Browser{browser: incognito, width: b.width, height: b.height, human: b.human, emulation: b.emulation}, nil
+}
+
+// isolateSession keeps the per-session state of run i of several concurrent
+// runs sharing options apart: artifacts, session, checkpoint, cookie and page
+// state files and downloads are numbered after the run, traces get their own
+// recorder under the same directory, and the memory is copied.
+func isolateSession(i int) Option {
+	return func(c *config) {
+		if c.sessionID != "" {
+			c.sessionID = fmt.Sprintf("%s-%d", c.sessionID, i+1)
+		}
+		c.sessionFile = indexedPath(c.sessionFile, i)
+		c.checkpointPath = indexedPath(c.checkpointPath, i)
+		c.cookieExportPath = indexedPath(c.cookieExportPath, i)
+		c.saveStatePath = indexedPath(c.saveStatePath, i)
+		if c.downloadDir != "" {
+			c.downloadDir = filepath.Join(c.downloadDir, strconv.Itoa(i+1))
+		}
+		if c.trace != nil {
+			c.trace = NewTraceRecorder(c.trace.root)
+		}
+		if c.memory != nil {
+			// The tools were bound to the shared memory, rebind them to the copy
+			mem := NewMemory()
+			for k, v := range c.memory.All() {
+				mem.Set(k, v)
+			}
+			c.memory = mem
+			c.tools = slices.Clone(c.tools)
+			for _, mt := range memoryTools(mem) {
+				for j, t := range c.tools {
+					if t.tool.Name == mt.tool.Name {
+						c.tools[j] = mt
+					}
+				}
+			}
+		}
+	}
+}
+
+// indexedPath numbers path after run i, e.g. session.json becomes session-2.json
+func indexedPath(path string, i int) string {
+	if path == "" {
+		return ""
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), i+1, ext)
+}
@@ -0,0 +1,59 @@
+package computeruse
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestIsolateSession(t *testing.T) {
+	mem := NewMemory()
+	mem.Set("order", "A-42")
+	trace := NewTraceRecorder("traces")
+	opts := []Option{
+		WithArtifacts("", "run"),
+		WithSessionFile("session.json"),
+		WithResilience(Resilient, 3, "checkpoint.json"),
+		WithCookieExport("cookies"),
+		WithSaveState("state.json"),
+		WithDownloads("downloads", nil),
+		WithTraceRecorder(trace),
+		WithMemory(mem),
+	}
+	cfg := newConfig(append(opts, isolateSession(1)))
+
+	for _, tt := range []struct{ name, got, want string }{
+		{"session id", cfg.sessionID, "run-2"},
+		{"session file", cfg.sessionFile, "session-2.json"},
+		{"checkpoint", cfg.checkpointPath, "checkpoint-2.json"},
+		{"cookie export", cfg.cookieExportPath, "cookies-2"},
+		{"saved state", cfg.saveStatePath, "state-2.json"},
+		{"downloads", cfg.downloadDir, filepath.Join("downloads", "2")},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+	if cfg.trace == trace || cfg.trace.root != "traces" {
+		t.Errorf("trace recorder is shared or moved: %+v", cfg.trace)
+	}
+	if cfg.memory == mem {
+		t.Fatal("memory is shared")
+	}
+	if v, _ := cfg.memory.Get("order"); v != "A-42" {
+		t.Errorf("copied memory lost its values: %v", cfg.memory.All())
+	}
+	// The memory tools must write to the copy
+	for _, tool := range cfg.tools {
+		if tool.tool.Name == "memory_write" {
+			if _, err := tool.call(t.Context(), nil, `{"key":"k","value":"v"}`); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, ok := cfg.memory.Get("k"); !ok {
+		t.Error("memory_write did not write to the copied memory")
+	}
+	if _, ok := mem.Get("k"); ok {
+		t.Error("memory_write wrote to the shared memory")
+	}
+}
@@ -0,0 +1,92 @@
+package computeruse
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+
+	"github.com/go-rod/rod"
+)
+
+// elementRectsJS returns the viewport bounding boxes of all visible elements matching the selectors
+const elementRectsJS = `(selectors) => {
+	const rects = [];
+	for (const selector of selectors) {
+		for (const el of document.querySelectorAll(selector)) {
+			const r = el.getBoundingClientRect();
+			if (r.width > 0 && r.height > 0) {
+				rects.push({x: Math.floor(r.left), y: Math.floor(r.top), w: Math.ceil(r.width), h: Math.ceil(r.height)});
+			}
+		}
+	}
+	return rects;
+}`
+
+// ElementRects returns the bounding boxes, in viewport pixels, of the elements matching any of the CSS selectors
+func (b *Browser) ElementRects(ctx context.Context, selectors []string) ([]image.Rectangle, error) {
+	var boxes []struct {
+		X int `json:"x"`
+		Y int `json:"y"`
+		W int `json:"w"`
+		H int `json:"h"`
+	}
+	err := b.do(ctx, func(page *rod.Page) error {
+		obj, err := page.Eval(elementRectsJS, selectors)
+		if err != nil {
+			return err
+		}
+		return obj.Value.Unmarshal(&boxes)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error locating privacy zones: %w", err)
+	}
+	rects := make([]image.Rectangle, 0, len(boxes))
+	for _, box := range boxes {
+		rects = append(rects, image.Rect(box.X, box.Y, box.X+box.W, box.Y+box.H))
+	}
+	return rects, nil
+}
+
+// redactScreenshot blacks out the rectangles in a PNG screenshot
+func redactScreenshot(screenshot []byte, rects []image.Rectangle) ([]byte, error) {
+	if len(rects) == 0 {
+		return screenshot, nil
+	}
+	src, err := png.Decode(bytes.NewReader(screenshot))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding screenshot: %w", err)
+	}
+	img := image.NewRGBA(src.Bounds())
+	draw.Draw(img, img.Bounds(), src, src.Bounds().Min, draw.Src)
+	black := image.NewUniform(color.Black)
+	for _, r := range rects {
+		draw.Draw(img, r.Intersect(img.Bounds()), black, image.Point{}, draw.Src)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("error encoding screenshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// privateScreenshot takes a screenshot with the configured privacy zones blacked out.
+// Zones that cannot be located fail the screenshot rather than risk leaking them.
+func privateScreenshot(ctx context.Context, c Computer, selectors []string) ([]byte, error) {
+	b, ok := c.(*Browser)
+	if !ok || len(selectors) == 0 {
+		return c.Screenshot(ctx)
+	}
+	rects, err := b.ElementRects(ctx, selectors)
+	if err != nil {
+		return nil, err
+	}
+	screenshot, err := b.Screenshot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return redactScreenshot(screenshot, rects)
+}
@@ -0,0 +1,55 @@
+package computeruse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// rateLimitReset returns how long until the rate limit that rejected a request
+// resets, taken from the Retry-After and x-ratelimit-reset-* headers. ok is
+// false for other errors and for exhausted quotas, which do not reset.
+func rateLimitReset(err error) (time.Duration, bool) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if strings.Contains(apiErr.Body, "insufficient_quota") {
+		return 0, false
+	}
+	wait, found := apiErr.RetryAfter()
+	// The reset headers use Go-like durations such as "1s" or "6m0s"
+	for _, name := range []string{"x-ratelimit-reset-requests", "x-ratelimit-reset-tokens"} {
+		if d, err := time.ParseDuration(apiErr.Header.Get(name)); err == nil {
+			found = true
+			if d > wait {
+				wait = d
+			}
+		}
+	}
+	return max(wait, 0), found
+}
+
+// waitForRateLimit pauses the session until the rate limit resets, keeping the
+// computer alive. It returns false without waiting when err is not a resettable
+// rate limit or the reset is further away than allowed.
+func waitForRateLimit(ctx context.Context, cfg *config, err error) bool {
+	if cfg.rateLimitWait <= 0 {
+		return false
+	}
+	wait, ok := rateLimitReset(err)
+	if !ok || wait > cfg.rateLimitWait {
+		return false
+	}
+	// Leave a little slack so the request does not race the reset
+	wait += time.Second
+	cfg.events.notice(fmt.Sprintf("⏸️ Rate limit exhausted, pausing for %s", wait))
+	if sleepContext(ctx, wait) != nil {
+		return false
+	}
+	cfg.events.notice("▶️ Resuming session")
+	return true
+}
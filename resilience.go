@@ -0,0 +1,168 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// APIError is returned when the OpenAI API responds with a non-200 status
+type APIError struct {
+	StatusCode int
+	// Type, Code and Message are parsed from the error object of the body, when present
+	Type    string
+	Code    string
+	Message string
+	// RequestID identifies the request for OpenAI support
+	RequestID string
+	Body      string
+	Header    http.Header
+}
+
+// newAPIError builds an APIError from a failed response
+func newAPIError(statusCode int, header http.Header, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: statusCode,
+		RequestID:  header.Get("x-request-id"),
+		Body:       string(body),
+		Header:     header,
+	}
+	var parsed struct {
+		Error struct {
+			Type    string `json:"type"`
+			Code    any    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil {
+		e.Type = parsed.Error.Type
+		e.Message = parsed.Error.Message
+		if parsed.Error.Code != nil {
+			e.Code = fmt.Sprint(parsed.Error.Code)
+		}
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API request failed with status code %d: %s", e.StatusCode, e.Body)
+	}
+	msg := fmt.Sprintf("API request failed with status code %d (%s): %s", e.StatusCode, e.Type, e.Message)
+	if e.RequestID != "" {
+		msg += " [request " + e.RequestID + "]"
+	}
+	return msg
+}
+
+// Temporary reports whether the request may succeed when retried
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Resilience selects how a run reacts to API errors
+type Resilience string
+
+const (
+	// Strict aborts the run on any API error that persists past the retry policy
+	Strict Resilience = "strict"
+	// Resilient additionally checkpoints the session when API errors persist
+	Resilient Resilience = "resilient"
+)
+
+// Checkpoint is the conversation state needed to resume an interrupted run
+type Checkpoint struct {
+	Model              string            `json:"model"`
+	Instruction        string            `json:"instruction"`
+	PreviousResponseID string            `json:"previous_response_id"`
+	Pending            []Input           `json:"pending"`
+	Turn               int               `json:"turn"`
+	CurrentURL         string            `json:"current_url,omitempty"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	Memory             map[string]string `json:"memory,omitempty"`
+}
+
+// CheckpointError is returned when a resilient run gives up and saved a checkpoint
+type CheckpointError struct {
+	Path string
+	Err  error
+}
+
+func (e *CheckpointError) Error() string {
+	return fmt.Sprintf("%v (session checkpoint saved to %s)", e.Err, e.Path)
+}
+
+func (e *CheckpointError) Unwrap() error {
+	return e.Err
+}
+
+// SaveCheckpoint writes a checkpoint as JSON
+func SaveCheckpoint(path string, cp *Checkpoint) error {
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding checkpoint: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("error saving checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint reads a checkpoint saved by a resilient run
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint: %w", err)
+	}
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("error decoding checkpoint: %w", err)
+	}
+	restorePending(cp.Pending)
+	return &cp, nil
+}
+
+// restorePending retypes decoded computer outputs, which decode as generic
+// maps, so they are re-encoded unchanged
+func restorePending(pending []Input) {
+	for i, in := range pending {
+		if in.Type == "computer_call_output" {
+			raw, _ := json.Marshal(in.Output)
+			var out ComputerOutput
+			if err := json.Unmarshal(raw, &out); err == nil {
+				pending[i].Output = &out
+			}
+		}
+	}
+}
+
+// createResponse calls the API, pausing while the rate limit is exhausted and
+// retrying transient failures according to the retry policy
+func createResponse(ctx context.Context, cfg *config, request Request) (*Response, error) {
+	ep := cfg.endpoint()
+	send := func() (*Response, error) {
+		if cfg.api != nil {
+			return cfg.api.CreateResponse(ctx, request)
+		}
+		if cfg.onStream != nil {
+			return sendResponseStream(ctx, ep, request, cfg.onStream)
+		}
+		return sendResponse(ctx, ep, request)
+	}
+	response, err := send()
+	for pauses := 0; err != nil && pauses < 3 && waitForRateLimit(ctx, cfg, err); pauses++ {
+		response, err = send()
+	}
+	for attempt := 1; err != nil && isTransient(ctx, err) && attempt <= cfg.retryPolicy.MaxRetries; attempt++ {
+		delay := cfg.retryPolicy.delay(attempt, err)
+		cfg.events.notice(fmt.Sprintf("⏳ API call failed (%v), retrying in %s", err, delay))
+		if serr := sleepContext(ctx, delay); serr != nil {
+			return nil, errors.Join(err, serr)
+		}
+		response, err = send()
+	}
+	return response, err
+}
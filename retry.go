@@ -0,0 +1,87 @@
+package computeruse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how transient API errors (network failures, 429 and
+// 5xx responses) are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt; zero disables retrying
+	MaxRetries int
+	// BaseDelay is the delay before the first retry, doubled for every further retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay and the honored Retry-After header
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy retries transient errors three times starting at one second
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	BaseDelay:  time.Second,
+	MaxDelay:   30 * time.Second,
+}
+
+// delay returns how long to wait before retry number attempt (starting at 1).
+// A Retry-After header of the failed response takes precedence over the
+// exponential backoff, which is jittered so concurrent sessions spread out.
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if d, ok := apiErr.RetryAfter(); ok {
+			return min(d, p.MaxDelay)
+		}
+	}
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	// Full jitter over the upper half of the backoff window
+	return d/2 + rand.N(d/2+1)
+}
+
+// RetryAfter returns the delay requested by the Retry-After (or retry-after-ms) header
+func (e *APIError) RetryAfter() (time.Duration, bool) {
+	if v := e.Header.Get("retry-after-ms"); v != "" {
+		if ms, err := strconv.Atoi(v); err == nil {
+			return time.Duration(ms) * time.Millisecond, true
+		}
+	}
+	if v := e.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil {
+			return time.Duration(secs) * time.Second, true
+		}
+		if t, err := http.ParseTime(v); err == nil {
+			return max(time.Until(t), 0), true
+		}
+	}
+	return 0, false
+}
+
+// isTransient reports whether an API call failure is worth retrying
+func isTransient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	// Only network failures are retried; a missing API key or a malformed
+	// request or response fails the same way every time
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE)
+}
@@ -0,0 +1,37 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestIsTransient(t *testing.T) {
+	syntaxErr := json.Unmarshal([]byte("{"), &struct{}{})
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"rate limited", &APIError{StatusCode: 429}, true},
+		{"server error", &APIError{StatusCode: 503}, true},
+		{"bad request", &APIError{StatusCode: 400}, false},
+		{"network timeout", fmt.Errorf("failed to send request: %w", &net.DNSError{IsTimeout: true}), true},
+		{"connection reset", fmt.Errorf("failed to send request: %w", syscall.ECONNRESET), true},
+		{"truncated body", fmt.Errorf("failed to read response body: %w", io.ErrUnexpectedEOF), true},
+		{"missing API key", errors.New("OPENAI_API_KEY environment variable is not set"), false},
+		{"malformed response", fmt.Errorf("failed to unmarshal response: %w", syntaxErr), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransient(context.Background(), tt.err); got != tt.want {
+				t.Errorf("isTransient(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
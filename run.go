@@ -0,0 +1,298 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Status is the terminal status of a run
+type Status string
+
+const (
+	StatusCompleted Status = "completed"
+	StatusMaxTurns  Status = "max_turns_exceeded"
+	StatusCanceled  Status = "canceled"
+	StatusFailed    Status = "failed"
+	// StatusBudgetExceeded means the run stopped at a limit set with WithBudget
+	StatusBudgetExceeded Status = "budget_exceeded"
+)
+
+// Result is the outcome of a run
+type Result struct {
+	SessionID string `json:"session_id"`
+	// Tags are the labels attached with WithTags
+	Tags   map[string]string `json:"tags,omitempty"`
+	Output string            `json:"output"`
+	Status Status            `json:"status"`
+	Turns  int               `json:"turns"`
+	// ResponseID is the last model response, from which a conversation can be continued
+	ResponseID   string    `json:"response_id,omitempty"`
+	ArtifactsDir string    `json:"artifacts_dir"`
+	Attempts     []Attempt `json:"attempts,omitempty"`
+	// SafetyChecks lists every safety check encountered and how it was resolved
+	SafetyChecks []SafetyCheckRecord `json:"safety_checks,omitempty"`
+	// Downloads lists the files downloaded with the data extracted by the download parsers
+	Downloads []DownloadResult `json:"downloads,omitempty"`
+	// FinalScreenshot and FinalURL capture the computer when the session ended
+	FinalScreenshot string `json:"final_screenshot,omitempty"`
+	FinalURL        string `json:"final_url,omitempty"`
+	// Actions lists every computer action taken, in order, with its screenshot
+	Actions []ActionRecord `json:"actions,omitempty"`
+	// Usage is the token usage summed over all API calls of the run
+	Usage UsageInfo `json:"usage"`
+	// StateFile is the page state saved by WithSaveState, to start a later session from
+	StateFile string `json:"state_file,omitempty"`
+	// CookieFile holds the cookies exported by WithCookieExport; see LoadCookies
+	CookieFile string `json:"cookie_file,omitempty"`
+	// Verification is the judge model's verdict on Output when WithAnswerVerification is used
+	Verification *Verification `json:"verification,omitempty"`
+	// Memory is what the model remembered with WithMemory when the run ended
+	Memory map[string]string `json:"memory,omitempty"`
+}
+
+// ActionRecord is a computer action taken during a run. A call of a function
+// tool such as memory_write is recorded with the action type "function_call".
+type ActionRecord struct {
+	Turn       int    `json:"turn"`
+	Action     Action `json:"action"`
+	URL        string `json:"url,omitempty"`
+	Screenshot string `json:"screenshot,omitempty"`
+	// Element is the DOM element hit by a click in a browser
+	Element *ElementInfo `json:"element,omitempty"`
+	// Function is the function tool call of a "function_call" record
+	Function *FunctionCallRecord `json:"function,omitempty"`
+	// Error is set when the action failed and the failure was reported to the model
+	Error string `json:"error,omitempty"`
+}
+
+// FunctionCallRecord is a call of a function tool and what it returned to the model
+type FunctionCallRecord struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Output    string `json:"output"`
+}
+
+// Attempt records one try of a task when WithTaskRetry is used
+type Attempt struct {
+	Instruction string `json:"instruction"`
+	Output      string `json:"output"`
+	Status      Status `json:"status"`
+	Turns       int    `json:"turns"`
+	Failure     string `json:"failure,omitempty"`
+}
+
+// Run executes the instruction against a Computer and returns the result.
+// With WithTaskRetry a failed run is restarted with a prompt describing what
+// went wrong, and every attempt is recorded in Result.Attempts.
+func Run(ctx context.Context, computer Computer, instruction string, maxTurns int, opts ...Option) (*Result, error) {
+	cfg := newConfig(opts)
+	if err := errors.Join(cfg.errs...); err != nil {
+		return nil, err
+	}
+	scale := cfg.screenScale(computer.Dimensions())
+	if err := checkModel(cfg.model, cfg.environmentOr(computer.Environment()), scale.width, scale.height); err != nil {
+		return nil, err
+	}
+
+	if p, ok := computer.(interface{ SetHumanPacing(bool) }); ok && cfg.humanPacing {
+		p.SetHumanPacing(true)
+	}
+
+	browser, isBrowser := computer.(*Browser)
+	if isBrowser && cfg.downloadDir != "" {
+		if err := browser.EnableDownloads(ctx, cfg.downloadDir); err != nil {
+			return nil, err
+		}
+		browser.OnDownload(cfg.events.download)
+	}
+	if isBrowser && len(cfg.uploadFiles) > 0 {
+		if err := browser.EnableFileChooser(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	if isBrowser && cfg.warmStart != nil {
+		if err := browser.RestoreState(ctx, cfg.warmStart); err != nil {
+			return nil, err
+		}
+	}
+
+	spent, err := newBudgetTracker(cfg.budget, cfg.model)
+	if err != nil {
+		return nil, err
+	}
+	cfg.spent = spent
+
+	rec := newArtifactRecorder(cfg.artifactsDir, cfg.sessionID, cfg.tags)
+	if err := cfg.trace.start(rec.manifest.SessionID, instruction, computer); err != nil {
+		cfg.events.error(err)
+	}
+	prompt := instruction
+	var attempts []Attempt
+	var safetyChecks []SafetyCheckRecord
+	var actions []ActionRecord
+	var usage UsageInfo
+	for attempt := 0; ; attempt++ {
+		// Setup steps run before every attempt, since a retry resets the computer
+		if len(cfg.setupActions) > 0 {
+			if _, err := runActions(ctx, computer, cfg.setupActions, cfg); err != nil {
+				result := &Result{SessionID: rec.manifest.SessionID, Status: StatusFailed, ArtifactsDir: rec.dir}
+				captureFinalState(ctx, computer, cfg, rec, result)
+				return result, fmt.Errorf("error running setup actions: %w", err)
+			}
+		}
+		result, err := runAttempt(ctx, computer, prompt, maxTurns, cfg, rec)
+		result.SessionID = rec.manifest.SessionID
+		result.Tags = cfg.tags
+		result.ArtifactsDir = rec.dir
+		// Keep the checks of earlier attempts for compliance review
+		safetyChecks = append(safetyChecks, result.SafetyChecks...)
+		result.SafetyChecks = safetyChecks
+		actions = append(actions, result.Actions...)
+		result.Actions = actions
+		usage = usage.add(result.Usage)
+		result.Usage = usage
+		failure := attemptFailure(result, err)
+		if failure == "" && cfg.critic != nil {
+			if cerr := cfg.critic(result.Output); cerr != nil {
+				failure = "the answer was rejected: " + cerr.Error()
+				result.Status = StatusFailed
+			}
+		}
+		attempts = append(attempts, Attempt{
+			Instruction: prompt,
+			Output:      result.Output,
+			Status:      result.Status,
+			Turns:       result.Turns,
+			Failure:     failure,
+		})
+		if cfg.taskRetries > 0 {
+			result.Attempts = attempts
+		}
+
+		if failure == "" || attempt >= cfg.taskRetries || !retryable(err) {
+			if isBrowser && cfg.downloadDir != "" {
+				result.Downloads = parseDownloads(browser.Downloads(ctx), cfg.downloadParsers)
+			}
+			captureFinalState(ctx, computer, cfg, rec, result)
+			if isBrowser && cfg.saveStatePath != "" {
+				savePageState(ctx, browser, cfg, result)
+			}
+			if isBrowser && cfg.cookieExportPath != "" {
+				exportCookies(ctx, browser, cfg, result)
+			}
+			if terr := cfg.trace.finish(result); terr != nil {
+				cfg.events.error(terr)
+			}
+			if ierr := writeSessionIndex(cfg.artifactsDir); ierr != nil {
+				cfg.events.error(ierr)
+			}
+			if err == nil && cfg.outputTarget != nil && result.Status == StatusCompleted {
+				if uerr := json.Unmarshal([]byte(extractJSON(result.Output)), cfg.outputTarget); uerr != nil {
+					err = fmt.Errorf("error decoding structured output: %w", uerr)
+				}
+			}
+			if err == nil && cfg.verifyModel != "" && result.Status == StatusCompleted {
+				// A failed verification leaves the answer unverified rather than failing the run
+				if v, verr := verifyAnswer(ctx, cfg, instruction, result); verr != nil {
+					cfg.events.error(verr)
+				} else {
+					cfg.events.notice(fmt.Sprintf("⚖️ Verification: %s (confidence %.2f)", v.Verdict, v.Confidence))
+					result.Verification = v
+				}
+			}
+			return result, err
+		}
+
+		cfg.events.notice(fmt.Sprintf("🔁 Attempt %d failed (%s), retrying", attempt+1, failure))
+		if cfg.reset != nil {
+			if err := cfg.reset(ctx); err != nil {
+				return result, fmt.Errorf("error resetting for retry: %w", err)
+			}
+		}
+		prompt = refinePrompt(instruction, attempts)
+	}
+}
+
+// captureFinalState saves a last screenshot and records it with the final URL
+// in the result, even when the run failed or ctx was canceled
+func captureFinalState(ctx context.Context, computer Computer, cfg *config, rec *artifactRecorder, result *Result) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.actionTimeout)
+	defer cancel()
+	if u, ok := computer.(urlReporter); ok {
+		result.FinalURL = u.GetCurrentUrl()
+	}
+	screenshot, err := privateScreenshot(ctx, computer, cfg.privacySelectors)
+	if err != nil {
+		cfg.events.error(fmt.Errorf("error taking final screenshot: %w", err))
+		return
+	}
+	out := &ComputerOutput{ImageURL: dataURL(screenshot), CurrentURL: result.FinalURL}
+	file, err := saveScreenshot(out, rec.stem("final"))
+	if err != nil {
+		cfg.events.error(err)
+		return
+	}
+	result.FinalScreenshot = file
+	cfg.events.screenshot(result.Turns, file, out)
+	cfg.observer.frame(result.Turns, file, out)
+	if err := rec.addFrame("final", file, result.FinalURL, nil); err != nil {
+		cfg.events.error(err)
+	}
+}
+
+// savePageState snapshots the browser page at the end of the run for WithSaveState
+func savePageState(ctx context.Context, browser *Browser, cfg *config, result *Result) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.actionTimeout)
+	defer cancel()
+	state, err := browser.CaptureState(ctx)
+	if err == nil {
+		err = SavePageState(cfg.saveStatePath, state)
+	}
+	if err != nil {
+		cfg.events.error(err)
+		return
+	}
+	result.StateFile = cfg.saveStatePath
+	cfg.events.notice("💾 Page state saved: " + cfg.saveStatePath)
+}
+
+// attemptFailure describes why an attempt failed, or returns "" when it succeeded
+func attemptFailure(result *Result, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if result.Status == StatusMaxTurns {
+		return fmt.Sprintf("no final answer was given within %d turns", result.Turns)
+	}
+	return ""
+}
+
+// retryable reports whether a failed attempt may be retried with a refined prompt.
+// Infrastructure errors and cancellation are returned to the caller instead.
+func retryable(err error) bool {
+	if err == nil {
+		return true
+	}
+	var answerErr *AnswerError
+	var loopErr *NavigationLoopError
+	return errors.As(err, &answerErr) || errors.As(err, &loopErr)
+}
+
+// refinePrompt augments the instruction with what went wrong in previous attempts
+func refinePrompt(instruction string, attempts []Attempt) string {
+	var sb strings.Builder
+	sb.WriteString(instruction)
+	sb.WriteString("\n\nPrevious attempts at this task failed:")
+	for i, a := range attempts {
+		fmt.Fprintf(&sb, "\n- Attempt %d: %s", i+1, a.Failure)
+		if a.Output != "" {
+			fmt.Fprintf(&sb, " (answer was: %q)", a.Output)
+		}
+	}
+	sb.WriteString("\nTake a different approach this time.")
+	return sb.String()
+}
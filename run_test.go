@@ -0,0 +1,266 @@
+package computeruse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeComputer is a Computer recording the actions it receives and returning
+// a blank screenshot
+type fakeComputer struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+func (c *fakeComputer) record(action string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.actions = append(c.actions, action)
+	return nil
+}
+
+func (c *fakeComputer) Environment() string                { return "browser" }
+func (c *fakeComputer) Dimensions() (int, int)             { return 1024, 768 }
+func (c *fakeComputer) GetCurrentUrl() string              { return "https://example.com/" }
+func (c *fakeComputer) Type(context.Context, string) error { return c.record("type") }
+func (c *fakeComputer) Click(context.Context, int, int, string) error {
+	return c.record("click")
+}
+func (c *fakeComputer) DoubleClick(context.Context, int, int) error { return c.record("double_click") }
+func (c *fakeComputer) Scroll(context.Context, int, int, int, int) error {
+	return c.record("scroll")
+}
+func (c *fakeComputer) Keypress(context.Context, []string) error { return c.record("keypress") }
+func (c *fakeComputer) Move(context.Context, int, int) error     { return c.record("move") }
+func (c *fakeComputer) Wait(context.Context, int) error          { return c.record("wait") }
+
+func (c *fakeComputer) Screenshot(context.Context) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1024, 768))); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// testOptions keep a test run quiet and its artifacts in a temporary directory
+func testOptions(t *testing.T, api ResponsesAPI, opts ...Option) []Option {
+	return append([]Option{
+		WithResponsesAPI(api),
+		WithArtifacts(t.TempDir(), "test"),
+		WithEvents(&Events{}),
+	}, opts...)
+}
+
+func TestRunRecordsMemory(t *testing.T) {
+	api := NewScriptedResponses(
+		FunctionCallResponse("resp_1", "call_1", "memory_write", `{"key":"order","value":"A-42"}`),
+		MessageResponse("resp_2", "done"),
+	)
+	traces := t.TempDir()
+	result, err := Run(context.Background(), &fakeComputer{}, "remember the order", 5,
+		testOptions(t, api, WithMemory(nil), WithTraceRecorder(NewTraceRecorder(traces)))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := result.Memory["order"]; got != "A-42" {
+		t.Errorf("Memory[order] = %q, want A-42", got)
+	}
+	if len(result.Actions) != 1 || result.Actions[0].Function == nil || result.Actions[0].Function.Name != "memory_write" {
+		t.Fatalf("Actions = %+v, want the memory_write call", result.Actions)
+	}
+	if out := result.Actions[0].Function.Output; out != "stored" {
+		t.Errorf("function output = %q, want stored", out)
+	}
+	trace, err := LoadTrace(filepath.Join(traces, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.Steps) != 1 || trace.Steps[0].Function == nil {
+		t.Errorf("trace steps = %+v, want the memory_write call", trace.Steps)
+	}
+}
+
+func TestRunRestoresMemoryFromCheckpoint(t *testing.T) {
+	api := NewScriptedResponses(
+		FunctionCallResponse("resp_2", "call_2", "memory_read", `{"key":"order"}`),
+		MessageResponse("resp_3", "done"),
+	)
+	cp := &Checkpoint{PreviousResponseID: "resp_1", Turn: 1, Memory: map[string]string{"order": "A-42"}}
+	result, err := Run(context.Background(), &fakeComputer{}, "remember the order", 5,
+		testOptions(t, api, WithMemory(nil), WithCheckpoint(cp))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Actions) != 1 || result.Actions[0].Function.Output != "A-42" {
+		t.Errorf("Actions = %+v, want memory_read returning A-42", result.Actions)
+	}
+	if got := api.Requests()[0].PreviousResponseID; got != "resp_1" {
+		t.Errorf("PreviousResponseID = %q, want resp_1", got)
+	}
+}
+
+func TestRunScripted(t *testing.T) {
+	check := SafetyCheck{ID: "sc_1", Code: "malicious_instructions", Message: "Check the page"}
+	rateLimited := &APIError{StatusCode: 429, Body: "rate limited"}
+	retry := WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	t.Run("multi-turn with safety check and 429 retries", func(t *testing.T) {
+		api := NewScriptedResponses().
+			Fail(rateLimited).
+			Reply(ComputerCallResponse("resp_1", "call_1", Action{Type: "click", X: 100, Y: 200, Button: "left"}, check)).
+			Fail(rateLimited).
+			Reply(ComputerCallResponse("resp_2", "call_2", Action{Type: "type", Text: "hello"})).
+			Reply(MessageResponse("resp_3", "done"))
+		var handled []SafetyCheckRecord
+		handler := func(ctx context.Context, record SafetyCheckRecord, action *Action) bool {
+			handled = append(handled, record)
+			return true
+		}
+		computer := &fakeComputer{}
+		result, err := Run(context.Background(), computer, "search", 5, testOptions(t, api, retry, WithSafetyCheckHandler(handler))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != StatusCompleted || result.Output != "done" || result.Turns != 3 {
+			t.Errorf("result = %s %q after %d turns, want completed \"done\" after 3", result.Status, result.Output, result.Turns)
+		}
+		if want := []string{"click", "type"}; !slices.Equal(computer.actions, want) {
+			t.Errorf("computer actions = %v, want %v", computer.actions, want)
+		}
+		if len(handled) != 1 || handled[0].ID != "sc_1" || handled[0].Action != "click" {
+			t.Errorf("handled checks = %+v, want sc_1 on the click", handled)
+		}
+		if len(result.SafetyChecks) != 1 || result.SafetyChecks[0].Resolution != SafetyAcknowledged {
+			t.Errorf("SafetyChecks = %+v, want sc_1 acknowledged", result.SafetyChecks)
+		}
+		if api.Remaining() != 0 {
+			t.Errorf("%d scripted replies left", api.Remaining())
+		}
+
+		requests := api.Requests()
+		if len(requests) != 5 {
+			t.Fatalf("got %d requests, want 5", len(requests))
+		}
+		// The request retried after the 429 continues from the first response
+		// and acknowledges the check in the output of the click
+		second := requests[3]
+		if second.PreviousResponseID != "resp_1" {
+			t.Errorf("PreviousResponseID = %q, want resp_1", second.PreviousResponseID)
+		}
+		if out := second.Input[0]; out.CallID != "call_1" || !slices.Equal(out.AcknowledgedSafetyChecks, []SafetyCheck{check}) {
+			t.Errorf("first input = %+v, want call_1 output acknowledging sc_1", out)
+		}
+		if got := requests[4].PreviousResponseID; got != "resp_2" {
+			t.Errorf("last PreviousResponseID = %q, want resp_2", got)
+		}
+	})
+
+	t.Run("unacknowledged safety check stops the run", func(t *testing.T) {
+		api := NewScriptedResponses(ComputerCallResponse("resp_1", "call_1", Action{Type: "click", X: 1, Y: 1}, check))
+		computer := &fakeComputer{}
+		result, err := Run(context.Background(), computer, "search", 5, testOptions(t, api, retry)...)
+		var scErr *SafetyCheckError
+		if !errors.As(err, &scErr) || scErr.Check.ID != "sc_1" {
+			t.Fatalf("err = %v, want SafetyCheckError for sc_1", err)
+		}
+		if len(computer.actions) != 0 {
+			t.Errorf("computer actions = %v, want none", computer.actions)
+		}
+		if result == nil || result.Status != StatusFailed {
+			t.Errorf("result = %+v, want failed", result)
+		}
+	})
+
+	t.Run("429 beyond the retries fails the run", func(t *testing.T) {
+		api := NewScriptedResponses().Fail(rateLimited).Fail(rateLimited).Fail(rateLimited)
+		_, err := Run(context.Background(), &fakeComputer{}, "search", 5, testOptions(t, api, retry)...)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != 429 {
+			t.Fatalf("err = %v, want the 429 APIError", err)
+		}
+		if api.Remaining() != 0 {
+			t.Errorf("%d scripted replies left, want all 3 attempts used", api.Remaining())
+		}
+	})
+}
+
+func TestCheckpointStoresTask(t *testing.T) {
+	api := NewScriptedResponses().Fail(errors.New("connection reset"))
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	_, err := Run(context.Background(), &fakeComputer{}, "find the price", 5,
+		testOptions(t, api, WithAnswerLanguage("French", 0), WithResilience(Resilient, 0, path))...)
+	var cpErr *CheckpointError
+	if !errors.As(err, &cpErr) {
+		t.Fatalf("err = %v, want CheckpointError", err)
+	}
+	cp, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Instruction != "find the price" {
+		t.Errorf("checkpoint instruction = %q, want the task without the added instructions", cp.Instruction)
+	}
+}
+
+func TestCheckpointCountsResumedTurns(t *testing.T) {
+	api := NewScriptedResponses().
+		Reply(ComputerCallResponse("resp_4", "call_4", Action{Type: "wait"})).
+		Fail(errors.New("connection reset"))
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	resumed := &Checkpoint{Instruction: "find the price", PreviousResponseID: "resp_3", Turn: 3}
+	_, err := Run(context.Background(), &fakeComputer{}, "find the price", 5,
+		testOptions(t, api, WithCheckpoint(resumed), WithResilience(Resilient, 0, path))...)
+	var cpErr *CheckpointError
+	if !errors.As(err, &cpErr) {
+		t.Fatalf("err = %v, want CheckpointError", err)
+	}
+	cp, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Turn != 4 {
+		t.Errorf("checkpoint turn = %d, want 4 after one more turn of a session resumed at 3", cp.Turn)
+	}
+}
+
+func TestRunIncompleteWithoutOutput(t *testing.T) {
+	incomplete := func(id string) *Response {
+		return &Response{ID: id, Object: "response", Status: "incomplete",
+			IncompleteDetails: map[string]any{"reason": "max_output_tokens"}}
+	}
+
+	t.Run("continues once", func(t *testing.T) {
+		api := NewScriptedResponses(incomplete("resp_1"), MessageResponse("resp_2", "done"))
+		result, err := Run(context.Background(), &fakeComputer{}, "search", 5, testOptions(t, api)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Output != "done" {
+			t.Errorf("Output = %q, want done", result.Output)
+		}
+		next := api.Requests()[1]
+		if next.PreviousResponseID != "resp_1" || len(next.Input) != 1 || next.Input[0].Role != "user" {
+			t.Errorf("request after the incomplete response = %+v, want a user message continuing resp_1", next)
+		}
+	})
+
+	t.Run("fails when it stalls again", func(t *testing.T) {
+		api := NewScriptedResponses(incomplete("resp_1"), incomplete("resp_2"))
+		result, err := Run(context.Background(), &fakeComputer{}, "search", 5, testOptions(t, api)...)
+		if err == nil || !strings.Contains(err.Error(), "max_output_tokens") {
+			t.Fatalf("err = %v, want the stalled response reported", err)
+		}
+		if result.Status != StatusFailed {
+			t.Errorf("Status = %s, want failed", result.Status)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Resolutions of a pending safety check
+const (
+	SafetyAcknowledged     = "acknowledged"
+	SafetyAutoAcknowledged = "auto_acknowledged"
+	SafetyRejected         = "rejected"
+)
+
+// SafetyCheckRecord is a safety check encountered during a run and how it was resolved
+type SafetyCheckRecord struct {
+	SafetyCheck
+	Turn       int    `json:"turn"`
+	CallID     string `json:"call_id"`
+	Action     string `json:"action"`
+	URL        string `json:"url,omitempty"`
+	Screenshot string `json:"screenshot,omitempty"`
+	Resolution string `json:"resolution"`
+}
+
+// SafetyCheckHandler decides whether to acknowledge a pending safety check and
+// let the action proceed. The record carries the check with the page URL and
+// the path of the latest screenshot. Returning false aborts the run.
+type SafetyCheckHandler func(ctx context.Context, record SafetyCheckRecord, action *Action) bool
+
+// AutoAcknowledge acknowledges every safety check without asking. It turns off
+// the API's safety gate, so use it only for trusted, supervised environments.
+func AutoAcknowledge(ctx context.Context, record SafetyCheckRecord, action *Action) bool {
+	return true
+}
+
+// isAutoAcknowledge reports whether handler is AutoAcknowledge, whose checks
+// are recorded as SafetyAutoAcknowledged
+func isAutoAcknowledge(handler SafetyCheckHandler) bool {
+	return handler != nil && reflect.ValueOf(handler).Pointer() == reflect.ValueOf(AutoAcknowledge).Pointer()
+}
+
+// TerminalSafetyCheckHandler asks an operator on out whether to continue,
+// showing the check message and the latest screenshot path, and reads the
+// y/n answer from in, which it may share with TerminalApproval. Anything but
+// "y" or "yes" aborts the run.
+func TerminalSafetyCheckHandler(in io.Reader, out io.Writer) SafetyCheckHandler {
+	lines := terminalLines(in)
+	return func(ctx context.Context, record SafetyCheckRecord, action *Action) bool {
+		fmt.Fprintf(out, "\n⚠️ Safety check (%s): %s\n", record.Code, record.Message)
+		fmt.Fprintf(out, "   Action    : %s\n", record.Action)
+		if record.URL != "" {
+			fmt.Fprintf(out, "   URL       : %s\n", record.URL)
+		}
+		if record.Screenshot != "" {
+			fmt.Fprintf(out, "   Screenshot: %s\n", record.Screenshot)
+		}
+		fmt.Fprint(out, "Continue? [y/N]: ")
+
+		a, err := lines.readLine(ctx)
+		if err != nil {
+			fmt.Fprintln(out)
+			return false
+		}
+		a = strings.ToLower(a)
+		return a == "y" || a == "yes"
+	}
+}
+
+// SafetyCheckError is returned when a safety check was not acknowledged
+type SafetyCheckError struct {
+	Check SafetyCheck
+}
+
+func (e *SafetyCheckError) Error() string {
+	return fmt.Sprintf("safety check %s not acknowledged: %s", e.Check.Code, e.Check.Message)
+}
+
+// resolveSafetyChecks runs the handler on every pending check of a computer call.
+// It returns the checks to acknowledge in the call output, and the records for the result.
+func resolveSafetyChecks(ctx context.Context, cfg *config, o OutputItem, turn int, url, screenshot string) ([]SafetyCheck, []SafetyCheckRecord, error) {
+	var acknowledged []SafetyCheck
+	var records []SafetyCheckRecord
+	for _, check := range o.PendingSafetyChecks {
+		record := SafetyCheckRecord{
+			SafetyCheck: check,
+			Turn:        turn,
+			CallID:      o.CallID,
+			Action:      o.Action.Type,
+			URL:         url,
+			Screenshot:  screenshot,
+			Resolution:  SafetyRejected,
+		}
+		// Without a handler nobody vouched for the action, so it must not proceed
+		if cfg.safetyHandler != nil && cfg.safetyHandler(ctx, record, o.Action) {
+			record.Resolution = SafetyAcknowledged
+			if isAutoAcknowledge(cfg.safetyHandler) {
+				record.Resolution = SafetyAutoAcknowledged
+			}
+		}
+		records = append(records, record)
+		cfg.events.safetyCheck(record)
+
+		if record.Resolution == SafetyRejected {
+			return nil, records, &SafetyCheckError{Check: check}
+		}
+		acknowledged = append(acknowledged, check)
+	}
+	return acknowledged, records, nil
+}
@@ -0,0 +1,44 @@
+package computeruse
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestResolveSafetyChecks(t *testing.T) {
+	item := OutputItem{
+		CallID:              "call_1",
+		Action:              &Action{Type: "click"},
+		PendingSafetyChecks: []SafetyCheck{{ID: "sc_1", Code: "malicious_instructions"}},
+	}
+	reject := func(context.Context, SafetyCheckRecord, *Action) bool { return false }
+	accept := func(context.Context, SafetyCheckRecord, *Action) bool { return true }
+
+	tests := []struct {
+		name    string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{name: "no handler", want: SafetyRejected, wantErr: true},
+		{name: "rejecting handler", opts: []Option{WithSafetyCheckHandler(reject)}, want: SafetyRejected, wantErr: true},
+		{name: "accepting handler", opts: []Option{WithSafetyCheckHandler(accept)}, want: SafetyAcknowledged},
+		{name: "auto acknowledge", opts: []Option{WithSafetyCheckHandler(AutoAcknowledge)}, want: SafetyAutoAcknowledged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acked, records, err := resolveSafetyChecks(context.Background(), newConfig(tt.opts), item, 1, "", "")
+			var scErr *SafetyCheckError
+			if got := errors.As(err, &scErr); got != tt.wantErr {
+				t.Fatalf("err = %v, want SafetyCheckError %v", err, tt.wantErr)
+			}
+			if len(records) != 1 || records[0].Resolution != tt.want {
+				t.Fatalf("records = %+v, want resolution %s", records, tt.want)
+			}
+			if !tt.wantErr && len(acked) != 1 {
+				t.Errorf("acknowledged = %v, want the pending check", acked)
+			}
+		})
+	}
+}
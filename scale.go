@@ -0,0 +1,112 @@
+package computeruse
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+)
+
+// screenScale maps between the display the model sees and the real screen.
+// Screenshots larger than the model's input size are shrunk by factor, and
+// coordinates from the model are enlarged by 1/factor before they are executed.
+type screenScale struct {
+	factor float64
+	// width and height are the display dimensions declared to the model
+	width, height int
+}
+
+// newScreenScale fits a width x height screen into maxWidth x maxHeight,
+// keeping the aspect ratio. Zero limits and screens that already fit are not scaled.
+func newScreenScale(width, height, maxWidth, maxHeight int) screenScale {
+	factor := 1.0
+	if maxWidth > 0 && width > maxWidth {
+		factor = min(factor, float64(maxWidth)/float64(width))
+	}
+	if maxHeight > 0 && height > maxHeight {
+		factor = min(factor, float64(maxHeight)/float64(height))
+	}
+	if factor == 1 {
+		return screenScale{factor: 1, width: width, height: height}
+	}
+	return screenScale{
+		factor: factor,
+		width:  int(math.Round(float64(width) * factor)),
+		height: int(math.Round(float64(height) * factor)),
+	}
+}
+
+// screenScale returns the scaling for a width x height screen, limited by
+// WithMaxScreenshotSize or else the optimal display size of the model
+func (c *config) screenScale(width, height int) screenScale {
+	maxWidth, maxHeight := c.maxScreenshotWidth, c.maxScreenshotHeight
+	if maxWidth == 0 && maxHeight == 0 {
+		if info, ok := LookupModel(c.model); ok {
+			maxWidth, maxHeight = info.OptimalDisplayWidth, info.OptimalDisplayHeight
+		}
+	}
+	return newScreenScale(width, height, maxWidth, maxHeight)
+}
+
+// toScreen converts an action from model to screen coordinates
+func (s screenScale) toScreen(a Action) Action {
+	if s.factor == 1 {
+		return a
+	}
+	up := func(v int) int { return int(math.Round(float64(v) / s.factor)) }
+	a.X, a.Y = up(a.X), up(a.Y)
+	a.ScrollX, a.ScrollY = up(a.ScrollX), up(a.ScrollY)
+	if len(a.Path) > 0 {
+		path := make([]Point, len(a.Path))
+		for i, p := range a.Path {
+			path[i] = Point{X: up(p.X), Y: up(p.Y)}
+		}
+		a.Path = path
+	}
+	return a
+}
+
+// shrink resizes a PNG screenshot to the model's display size
+func (s screenScale) shrink(screenshot []byte) ([]byte, error) {
+	if s.factor == 1 {
+		return screenshot, nil
+	}
+	src, err := png.Decode(bytes.NewReader(screenshot))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding screenshot: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, resizeBox(src, s.width, s.height)); err != nil {
+		return nil, fmt.Errorf("error encoding screenshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// resizeBox downscales src to width x height, averaging the source pixels
+// covered by each destination pixel so text stays legible
+func resizeBox(src image.Image, width, height int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		y0 := b.Min.Y + y*b.Dy()/height
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/height)
+		for x := 0; x < width; x++ {
+			x0 := b.Min.X + x*b.Dx()/width
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/width)
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, bl, a, n = r+pr, g+pg, bl+pb, a+pa, n+1
+				}
+			}
+			i := dst.PixOffset(x, y)
+			dst.Pix[i+0] = uint8(r / n >> 8)
+			dst.Pix[i+1] = uint8(g / n >> 8)
+			dst.Pix[i+2] = uint8(bl / n >> 8)
+			dst.Pix[i+3] = uint8(a / n >> 8)
+		}
+	}
+	return dst
+}
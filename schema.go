@@ -0,0 +1,304 @@
+package computeruse
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// schemaInstruction is appended to the task so the model answers with JSON matching schema
+func schemaInstruction(schema map[string]any) string {
+	data, _ := json.Marshal(schema)
+	return "Write your final answer as a single JSON value, without any other text, matching this JSON schema:\n" + string(data)
+}
+
+// schemaValidator asks the model to repair final answers that do not match schema
+func schemaValidator(schema map[string]any, attempts int) *answerValidator {
+	schema = normalizeSchema(schema)
+	return &answerValidator{
+		name: "schema",
+		check: func(answer string) error {
+			var v any
+			if err := json.Unmarshal([]byte(extractJSON(answer)), &v); err != nil {
+				return fmt.Errorf("answer is not valid JSON: %w", err)
+			}
+			return validateSchema(schema, v, "$")
+		},
+		repair: func(err error) string {
+			return fmt.Sprintf("Your answer does not match the required JSON schema: %v. "+
+				"Reply with only the corrected JSON, without any other text.", err)
+		},
+		retries: attempts,
+		strict:  true,
+	}
+}
+
+// normalizeSchema round-trips schema through JSON so Go literals such as
+// []string{"a"} become the []any values validateSchema expects
+func normalizeSchema(schema map[string]any) map[string]any {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return schema
+	}
+	var normalized map[string]any
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return schema
+	}
+	return normalized
+}
+
+// extractJSON strips markdown code fences and surrounding prose from a JSON answer
+func extractJSON(answer string) string {
+	s := strings.TrimSpace(answer)
+	if strings.HasPrefix(s, "```") {
+		s = strings.TrimPrefix(s, "```")
+		s = strings.TrimPrefix(s, "json")
+		s = strings.TrimSuffix(strings.TrimSpace(s), "```")
+		s = strings.TrimSpace(s)
+	}
+	start := strings.IndexAny(s, "{[")
+	end := strings.LastIndexAny(s, "}]")
+	if start >= 0 && end > start {
+		return s[start : end+1]
+	}
+	return s
+}
+
+// validateSchema checks v against the commonly used subset of JSON schema:
+// type, properties, required, additionalProperties, items and enum
+func validateSchema(schema map[string]any, v any, path string) error {
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if fmt.Sprint(e) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value %v is not one of %v", path, v, enum)
+		}
+	}
+
+	if t, ok := schema["type"]; ok && !matchesSchemaType(t, v) {
+		return fmt.Errorf("%s: expected %v, got %s", path, t, jsonTypeName(v))
+	}
+
+	switch val := v.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, r := range required {
+				name := fmt.Sprint(r)
+				if _, ok := val[name]; !ok {
+					return fmt.Errorf("%s: missing required property %q", path, name)
+				}
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			sub, ok := props[k].(map[string]any)
+			if !ok {
+				if allowed, ok := schema["additionalProperties"].(bool); ok && !allowed {
+					return fmt.Errorf("%s: unexpected property %q", path, k)
+				}
+				continue
+			}
+			if err := validateSchema(sub, val[k], path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range val {
+				if err := validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchesSchemaType reports whether v has the schema type t, which may be a string or a list of strings
+func matchesSchemaType(t any, v any) bool {
+	types, ok := t.([]any)
+	if !ok {
+		types = []any{t}
+	}
+	for _, t := range types {
+		name := fmt.Sprint(t)
+		actual := jsonTypeName(v)
+		if name == actual || (name == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonTypeName returns the JSON schema type name of a decoded JSON value
+func jsonTypeName(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if val == math.Trunc(val) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// SchemaFor reflects a JSON schema for strict structured output from the type
+// of v, typically a pointer to a struct. Fields are named by their json tags;
+// every field is required and pointer fields may be null.
+func SchemaFor(v any) (map[string]any, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, fmt.Errorf("cannot reflect a schema from nil")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("structured output must be an object, got %s", t)
+	}
+	return typeSchema(t, map[reflect.Type]bool{})
+}
+
+// typeSchema returns the JSON schema of a Go type. visiting holds the structs
+// being reflected, since a recursive type has no finite schema.
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) (map[string]any, error) {
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		if typ, ok := schema["type"].(string); ok {
+			schema["type"] = []string{typ, "null"}
+		}
+		return schema, nil
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		// encoding/json reads []byte from a base64 string
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}, nil
+		}
+		items, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Struct:
+		if visiting[t] {
+			return nil, fmt.Errorf("recursive type %s has no schema", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		fields, err := structFields(t, 0, visiting)
+		if err != nil {
+			return nil, err
+		}
+		// As in encoding/json, a field hides the fields of the same name
+		// promoted from deeper embedded structs
+		depths := map[string]int{}
+		for _, f := range fields {
+			if d, ok := depths[f.name]; !ok || f.depth < d {
+				depths[f.name] = f.depth
+			}
+		}
+		properties := map[string]any{}
+		required := []string{}
+		for _, f := range fields {
+			if _, done := properties[f.name]; done || f.depth != depths[f.name] {
+				continue
+			}
+			properties[f.name] = f.schema
+			required = append(required, f.name)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"required":             required,
+			"additionalProperties": false,
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s", t)
+}
+
+// schemaField is a JSON property of a struct and the embedding depth it was found at
+type schemaField struct {
+	name   string
+	schema map[string]any
+	depth  int
+}
+
+// structFields returns the JSON properties of a struct in field order, with
+// the fields of untagged embedded structs promoted as encoding/json does
+func structFields(t reflect.Type, depth int, visiting map[reflect.Type]bool) ([]schemaField, error) {
+	var fields []schemaField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		embedded := f.Type
+		if embedded.Kind() == reflect.Pointer {
+			embedded = embedded.Elem()
+		}
+		if f.Anonymous && name == "" && embedded.Kind() == reflect.Struct {
+			if visiting[embedded] {
+				return nil, fmt.Errorf("recursive type %s has no schema", embedded)
+			}
+			visiting[embedded] = true
+			promoted, err := structFields(embedded, depth+1, visiting)
+			delete(visiting, embedded)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, promoted...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		schema, err := typeSchema(f.Type, visiting)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if desc := f.Tag.Get("description"); desc != "" {
+			schema["description"] = desc
+		}
+		fields = append(fields, schemaField{name: name, schema: schema, depth: depth})
+	}
+	return fields, nil
+}
@@ -0,0 +1,41 @@
+package computeruse
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type schemaNode struct {
+	Name     string       `json:"name"`
+	Children []schemaNode `json:"children"`
+}
+
+type schemaBase struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type schemaProduct struct {
+	schemaBase
+	Name  string `json:"name" description:"Product name"`
+	Photo []byte `json:"photo"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	if _, err := SchemaFor(&schemaNode{}); err == nil || !strings.Contains(err.Error(), "recursive") {
+		t.Errorf("recursive type: err = %v, want a recursion error", err)
+	}
+
+	schema, err := SchemaFor(&schemaProduct{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := json.Marshal(schema)
+	want := `{"additionalProperties":false,` +
+		`"properties":{"id":{"type":"integer"},"name":{"description":"Product name","type":"string"},"photo":{"type":"string"}},` +
+		`"required":["id","name","photo"],"type":"object"}`
+	if string(got) != want {
+		t.Errorf("schema = %s\nwant     %s", got, want)
+	}
+}
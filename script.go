@@ -0,0 +1,41 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+)
+
+// RunActions executes a hand-written sequence of actions against a computer
+// without involving the model, e.g. deterministic login or navigation steps.
+// Every action is validated before the first one runs, and the sequence stops
+// at the first failing action. The outputs of the completed actions are returned.
+// Options that affect execution, such as WithActionTimeout, WithActionDelays and
+// WithPrivacyZones, apply as in a model-driven run.
+func RunActions(ctx context.Context, c Computer, actions []Action, opts ...Option) ([]*ComputerOutput, error) {
+	cfg := newConfig(opts)
+	return runActions(ctx, c, actions, cfg)
+}
+
+func runActions(ctx context.Context, c Computer, actions []Action, cfg *config) ([]*ComputerOutput, error) {
+	// Scripted actions use screen coordinates and full-size screenshots
+	width, height := c.Dimensions()
+	scale := newScreenScale(width, height, 0, 0)
+	for i, action := range actions {
+		if err := action.ValidateFor(width, height); err != nil {
+			return nil, fmt.Errorf("invalid action #%d: %w", i+1, err)
+		}
+	}
+
+	var outputs []*ComputerOutput
+	var lastFrame string
+	for i := range actions {
+		cfg.events.notice(fmt.Sprintf("📜 Scripted action #%d: %s", i+1, actions[i].Type))
+		out, err := computerCall(ctx, c, &actions[i], cfg, scale, lastFrame)
+		if err != nil {
+			return outputs, fmt.Errorf("scripted action #%d: %w", i+1, err)
+		}
+		lastFrame = out.ImageURL
+		outputs = append(outputs, out)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,132 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// ResponsesAPI creates model responses. The OpenAI API is used unless
+// WithResponsesAPI sets another implementation, such as ScriptedResponses.
+type ResponsesAPI interface {
+	CreateResponse(ctx context.Context, request Request) (*Response, error)
+}
+
+// ScriptedResponses is an in-memory ResponsesAPI that replies with a queue of
+// canned responses and errors, so multi-turn runs, safety checks and API
+// failures can be exercised without network access
+type ScriptedResponses struct {
+	mu      sync.Mutex
+	replies []scriptedReply
+	// requests are the requests received so far, in order
+	requests []Request
+}
+
+type scriptedReply struct {
+	response *Response
+	err      error
+}
+
+// NewScriptedResponses creates a scripted API replying with responses in order
+func NewScriptedResponses(responses ...*Response) *ScriptedResponses {
+	s := &ScriptedResponses{}
+	for _, r := range responses {
+		s.Reply(r)
+	}
+	return s
+}
+
+// Reply queues a response
+func (s *ScriptedResponses) Reply(response *Response) *ScriptedResponses {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, scriptedReply{response: response})
+	return s
+}
+
+// Fail queues an error, e.g. an *APIError with status 429 or 500 to exercise retries
+func (s *ScriptedResponses) Fail(err error) *ScriptedResponses {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies = append(s.replies, scriptedReply{err: err})
+	return s
+}
+
+// CreateResponse records the request and returns the next queued reply. It
+// fails once the queue is exhausted.
+func (s *ScriptedResponses) CreateResponse(ctx context.Context, request Request) (*Response, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, request)
+	if len(s.replies) == 0 {
+		return nil, fmt.Errorf("scripted responses exhausted after %d requests", len(s.requests))
+	}
+	reply := s.replies[0]
+	s.replies = s.replies[1:]
+	return reply.response, reply.err
+}
+
+// Requests returns the requests received so far
+func (s *ScriptedResponses) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Remaining returns the number of replies not yet consumed
+func (s *ScriptedResponses) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.replies)
+}
+
+// ComputerCallResponse builds a response asking for one computer action,
+// optionally with pending safety checks that must be acknowledged
+func ComputerCallResponse(id, callID string, action Action, checks ...SafetyCheck) *Response {
+	return &Response{
+		ID:     id,
+		Object: "response",
+		Status: "completed",
+		Output: []OutputItem{{
+			Type:                "computer_call",
+			ID:                  "cu_" + callID,
+			CallID:              callID,
+			Status:              "completed",
+			Action:              &action,
+			PendingSafetyChecks: checks,
+		}},
+	}
+}
+
+// FunctionCallResponse builds a response calling a function tool with JSON arguments
+func FunctionCallResponse(id, callID, name, arguments string) *Response {
+	return &Response{
+		ID:     id,
+		Object: "response",
+		Status: "completed",
+		Output: []OutputItem{{
+			Type:      "function_call",
+			CallID:    callID,
+			Name:      name,
+			Arguments: arguments,
+		}},
+	}
+}
+
+// MessageResponse builds a response with a final assistant message
+func MessageResponse(id, text string) *Response {
+	return &Response{
+		ID:     id,
+		Object: "response",
+		Status: "completed",
+		Output: []OutputItem{{
+			Type:    "message",
+			Role:    "assistant",
+			Status:  "completed",
+			Content: []any{map[string]any{"type": "output_text", "text": text}},
+		}},
+	}
+}
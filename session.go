@@ -0,0 +1,116 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Session is the state of a run saved after every turn with WithSessionFile,
+// so a run interrupted by a crash or restart can be continued with ResumeBrowserUse
+type Session struct {
+	Model              string            `json:"model"`
+	Instruction        string            `json:"instruction"`
+	MaxTurns           int               `json:"max_turns"`
+	Turn               int               `json:"turn"`
+	PreviousResponseID string            `json:"previous_response_id"`
+	LastCallID         string            `json:"last_call_id,omitempty"`
+	Screenshot         string            `json:"screenshot,omitempty"`
+	CurrentURL         string            `json:"current_url,omitempty"`
+	Pending            []Input           `json:"pending"`
+	Tags               map[string]string `json:"tags,omitempty"`
+	Memory             map[string]string `json:"memory,omitempty"`
+	Status             Status            `json:"status"`
+	Output             string            `json:"output,omitempty"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+}
+
+// SaveSession writes a session as JSON. The file is replaced atomically, so a
+// crash while saving leaves the previous turn's state intact.
+func SaveSession(path string, s *Session) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding session: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".session-*")
+	if err != nil {
+		return fmt.Errorf("error saving session: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error saving session: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error saving session: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error saving session: %w", err)
+	}
+	return nil
+}
+
+// LoadSession reads a session saved with WithSessionFile
+func LoadSession(path string) (*Session, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading session: %w", err)
+	}
+	var s Session
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("error decoding session: %w", err)
+	}
+	restorePending(s.Pending)
+	return &s, nil
+}
+
+// ResumeBrowserUse continues the session saved in sessionFile: it opens a
+// browser at the session's last URL and carries on the conversation through
+// its previous response ID for the turns that remain. The session file keeps
+// being updated, so a resumed run can itself be resumed.
+func ResumeBrowserUse(ctx context.Context, sessionFile string, opts ...Option) (*Result, error) {
+	return NewClient().ResumeBrowserUse(ctx, sessionFile, opts...)
+}
+
+// ResumeBrowserUse continues a saved session; see the package-level ResumeBrowserUse
+func (c *Client) ResumeBrowserUse(ctx context.Context, sessionFile string, opts ...Option) (*Result, error) {
+	s, err := LoadSession(sessionFile)
+	if err != nil {
+		return nil, err
+	}
+	if s.Status != StatusMaxTurns {
+		return nil, fmt.Errorf("session %s has already ended with status %s", sessionFile, s.Status)
+	}
+	if s.CurrentURL == "" {
+		return nil, fmt.Errorf("session %s has no URL to resume at", sessionFile)
+	}
+	remaining := s.MaxTurns - s.Turn
+	if remaining <= 0 {
+		return nil, fmt.Errorf("session %s has no turns left", sessionFile)
+	}
+	resume := []Option{
+		WithModel(s.Model),
+		WithCheckpoint(&Checkpoint{
+			Model:              s.Model,
+			Instruction:        s.Instruction,
+			PreviousResponseID: s.PreviousResponseID,
+			Pending:            s.Pending,
+			Turn:               s.Turn,
+			CurrentURL:         s.CurrentURL,
+			Tags:               s.Tags,
+			Memory:             s.Memory,
+		}),
+		WithSessionFile(sessionFile),
+	}
+	if len(s.Tags) > 0 {
+		resume = append(resume, WithTags(s.Tags))
+	}
+	// The model may still read what it remembered, so keep offering the memory
+	if len(s.Memory) > 0 && newConfig(c.options(opts)).memory == nil {
+		resume = append(resume, WithMemory(nil))
+	}
+	return c.BrowserUseResult(ctx, s.CurrentURL, s.Instruction, remaining, append(resume, opts...)...)
+}
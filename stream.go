@@ -0,0 +1,82 @@
+package computeruse
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StreamEvent is a server-sent event of a streamed response
+type StreamEvent struct {
+	// Type is the event type, e.g. "response.output_text.delta" or "response.output_item.done"
+	Type string `json:"type"`
+	// Delta holds the text increment of *.delta events
+	Delta string `json:"delta,omitempty"`
+	// Item holds the output item of response.output_item.* events; a finished
+	// computer_call can be executed before the rest of the response arrives
+	Item *OutputItem `json:"item,omitempty"`
+	// Response holds the response of response.created, response.completed and similar events
+	Response *Response `json:"response,omitempty"`
+	// Message holds the error message of error events
+	Message string `json:"message,omitempty"`
+}
+
+// CreateResponseStream sends a request with streaming enabled and calls onEvent
+// for every server-sent event as it arrives, so callers can display reasoning
+// and assistant text incrementally. It returns the completed response.
+func CreateResponseStream(request Request, onEvent func(StreamEvent)) (*Response, error) {
+	return sendResponseStream(context.Background(), defaultEndpoint(), request, onEvent)
+}
+
+// sendResponseStream posts a streaming request to an endpoint and parses the event stream
+func sendResponseStream(ctx context.Context, ep endpoint, request Request, onEvent func(StreamEvent)) (*Response, error) {
+	request.Stream = true
+	resp, err := postResponses(ctx, ep, request)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response *Response
+	scanner := bufio.NewScanner(resp.Body)
+	// Completed responses carry every output item and can be large
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line != "" {
+			// Only data lines matter; the event type is repeated in the payload
+			if payload, ok := strings.CutPrefix(line, "data:"); ok {
+				data.WriteString(strings.TrimPrefix(payload, " "))
+			}
+			continue
+		}
+		// A blank line ends the event
+		if data.Len() == 0 {
+			continue
+		}
+		var event StreamEvent
+		if err := json.Unmarshal([]byte(data.String()), &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal stream event: %w", err)
+		}
+		data.Reset()
+		if onEvent != nil {
+			onEvent(event)
+		}
+		switch event.Type {
+		case "response.completed", "response.incomplete", "response.failed":
+			response = event.Response
+		case "error":
+			return nil, fmt.Errorf("stream error: %s", event.Message)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response stream: %w", err)
+	}
+	if response == nil {
+		return nil, fmt.Errorf("response stream ended before the response completed")
+	}
+	return response, nil
+}
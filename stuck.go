@@ -0,0 +1,50 @@
+package computeruse
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+)
+
+// stuckDetector notices when the model repeats the same action without any visible effect
+type stuckDetector struct {
+	threshold  int
+	lastAction *Action
+	lastFrame  [32]byte
+	lastURL    string
+	repeats    int
+}
+
+// newStuckDetector returns nil when stuck detection is disabled
+func newStuckDetector(threshold int) *stuckDetector {
+	if threshold <= 0 {
+		return nil
+	}
+	return &stuckDetector{threshold: threshold}
+}
+
+// observe records an executed action and the screenshot taken after it, and
+// returns a nudge message once the same action left the screen and the URL unchanged threshold times in a row.
+// A reused frame says nothing about the effect of the action, so it is not counted.
+func (d *stuckDetector) observe(action *Action, out *ComputerOutput) string {
+	if d == nil || out.reused {
+		return ""
+	}
+	frame := sha256.Sum256([]byte(out.ImageURL))
+	if d.lastAction != nil && reflect.DeepEqual(*d.lastAction, *action) && frame == d.lastFrame && out.CurrentURL == d.lastURL {
+		d.repeats++
+	} else {
+		d.repeats = 1
+	}
+	d.lastAction = action
+	d.lastFrame = frame
+	d.lastURL = out.CurrentURL
+
+	if d.repeats < d.threshold {
+		return ""
+	}
+	d.repeats = 0
+	return fmt.Sprintf("You have repeated the same %s action %d times in a row and the screen did not change. "+
+		"This approach is not working; change your strategy (e.g. click a different element, scroll, or navigate elsewhere).",
+		action.Type, d.threshold)
+}
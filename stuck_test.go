@@ -0,0 +1,30 @@
+package computeruse
+
+import "testing"
+
+func TestStuckDetector(t *testing.T) {
+	click := &Action{Type: "click", Button: "left", X: 10, Y: 20}
+	frame := &ComputerOutput{ImageURL: "data:image/png;base64,AAAA", CurrentURL: "https://example.com/"}
+
+	d := newStuckDetector(3)
+	d.observe(click, frame)
+	d.observe(click, &ComputerOutput{ImageURL: frame.ImageURL, CurrentURL: "https://example.com/next"})
+	if nudge := d.observe(click, frame); nudge != "" {
+		t.Errorf("nudged although the URL changed: %q", nudge)
+	}
+
+	d = newStuckDetector(3)
+	wait := &Action{Type: "wait"}
+	for i := 0; i < 5; i++ {
+		if nudge := d.observe(wait, &ComputerOutput{ImageURL: frame.ImageURL, CurrentURL: frame.CurrentURL, reused: true}); nudge != "" {
+			t.Fatalf("nudged on a reused frame: %q", nudge)
+		}
+	}
+
+	d = newStuckDetector(3)
+	d.observe(click, frame)
+	d.observe(click, frame)
+	if nudge := d.observe(click, frame); nudge == "" {
+		t.Error("no nudge after the same click left the screen and URL unchanged three times")
+	}
+}
@@ -0,0 +1,190 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// TabInfo describes a browser tab. Index counts from 1 in the order the tabs were opened.
+type TabInfo struct {
+	Index  int    `json:"index"`
+	Title  string `json:"title"`
+	URL    string `json:"url"`
+	Active bool   `json:"active"`
+	// Count is the number of open tabs
+	Count int `json:"count"`
+	// Opened is set on the active tab when it became active on its own, because
+	// a popup opened or the previous tab was closed
+	Opened bool `json:"opened,omitempty"`
+}
+
+// watchTabs follows the tabs opened by the pages of the session, such as
+// popups and links with target=_blank. They are adopted on the next action,
+// which then runs on the newest tab.
+func (b *Browser) watchTabs() {
+	ctx, cancel := context.WithCancel(context.Background())
+	b.stopWatch = cancel
+	go b.browser.Context(ctx).EachEvent(func(e *proto.TargetTargetCreated) {
+		if e.TargetInfo.Type != proto.TargetTargetInfoTypePage {
+			return
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if b.ownsTarget(e.TargetInfo.OpenerID) {
+			b.openedTabs = append(b.openedTabs, e.TargetInfo.TargetID)
+		}
+	}, func(e *proto.TargetTargetDestroyed) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.closedTabs = append(b.closedTabs, e.TargetID)
+	})()
+}
+
+// ownsTarget reports whether id is a tab of the session; b.mu must be held
+func (b *Browser) ownsTarget(id proto.TargetTargetID) bool {
+	if id == "" {
+		return false
+	}
+	for _, tab := range b.tabs {
+		if tab.TargetID == id {
+			return true
+		}
+	}
+	return slices.Contains(b.openedTabs, id)
+}
+
+// activePage adopts the tabs opened and drops the tabs closed since the last
+// action, and returns the page actions should run on
+func (b *Browser) activePage() *rod.Page {
+	b.mu.Lock()
+	opened, closed := b.openedTabs, b.closedTabs
+	b.openedTabs, b.closedTabs = nil, nil
+	b.mu.Unlock()
+
+	for _, id := range opened {
+		if slices.Contains(closed, id) {
+			continue
+		}
+		page, err := b.adoptTab(id)
+		if err != nil {
+			continue
+		}
+		b.mu.Lock()
+		b.tabs = append(b.tabs, page)
+		b.mu.Unlock()
+		b.page = page
+		b.tabOpened = true
+	}
+	if len(closed) > 0 {
+		b.mu.Lock()
+		b.tabs = slices.DeleteFunc(b.tabs, func(tab *rod.Page) bool {
+			return slices.Contains(closed, tab.TargetID)
+		})
+		b.mu.Unlock()
+		if slices.Contains(closed, b.page.TargetID) && len(b.tabs) > 0 {
+			b.page = b.tabs[len(b.tabs)-1]
+			b.tabOpened = true
+		}
+	}
+	return b.page
+}
+
+// adoptTab attaches to a new tab and gives it the viewport of the session
+func (b *Browser) adoptTab(id proto.TargetTargetID) (*rod.Page, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	page, err := b.browser.Context(ctx).PageFromTarget(id)
+	if err != nil {
+		return nil, fmt.Errorf("error attaching to new tab: %w", err)
+	}
+	err = page.SetViewport(&proto.EmulationSetDeviceMetricsOverride{
+		Width:             b.width,
+		Height:            b.height,
+		DeviceScaleFactor: 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error setting viewport of new tab: %w", err)
+	}
+	if err := b.emulation.apply(page); err != nil {
+		return nil, err
+	}
+	if b.interceptChooser {
+		if err := b.interceptFileChooser(page); err != nil {
+			return nil, err
+		}
+	}
+	// Detach from ctx so later calls are bound by their own contexts
+	return page.Context(context.Background()), nil
+}
+
+// Tabs lists the open tabs of the session
+func (b *Browser) Tabs(ctx context.Context) ([]TabInfo, error) {
+	active := b.activePage()
+	tabs := make([]TabInfo, 0, len(b.tabs))
+	for i, tab := range b.tabs {
+		info, err := tab.Context(ctx).Info()
+		if err != nil {
+			return nil, fmt.Errorf("error reading tab %d: %w", i+1, err)
+		}
+		tabs = append(tabs, TabInfo{
+			Index:  i + 1,
+			Title:  info.Title,
+			URL:    info.URL,
+			Active: tab == active,
+			Count:  len(b.tabs),
+		})
+	}
+	return tabs, nil
+}
+
+// ActiveTab describes the tab actions run on. Opened is reported once after
+// the browser switched tabs on its own.
+func (b *Browser) ActiveTab(ctx context.Context) (*TabInfo, error) {
+	tabs, err := b.Tabs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tab := range tabs {
+		if tab.Active {
+			tab.Opened = b.tabOpened
+			b.tabOpened = false
+			return &tab, nil
+		}
+	}
+	return nil, fmt.Errorf("no active tab")
+}
+
+// SwitchTab makes the tab with the given index, counting from 1, the one
+// actions run on and brings it to the front
+func (b *Browser) SwitchTab(ctx context.Context, index int) error {
+	b.activePage()
+	if index < 1 || index > len(b.tabs) {
+		return fmt.Errorf("tab %d does not exist, %d tabs are open", index, len(b.tabs))
+	}
+	page := b.tabs[index-1]
+	if _, err := page.Context(ctx).Activate(); err != nil {
+		return fmt.Errorf("error switching to tab %d: %w", index, err)
+	}
+	b.page = page
+	b.tabOpened = false
+	return nil
+}
+
+// tabMessage tells the model which tab it is looking at when several are open
+// or the active tab changed without its doing. switchable offers switch_tab,
+// which the model only has with WithTabSwitching.
+func tabMessage(t *TabInfo, switchable bool) string {
+	msg := fmt.Sprintf("Tab %d of %d is active: %q at %s.", t.Index, t.Count, t.Title, t.URL)
+	if t.Opened {
+		msg = "The active tab changed because a tab was opened or closed. " + msg
+	}
+	if t.Count > 1 && switchable {
+		msg += " Use switch_tab to change tabs."
+	}
+	return msg
+}
@@ -0,0 +1,16 @@
+package computeruse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTabMessage(t *testing.T) {
+	tab := &TabInfo{Index: 2, Count: 2, Title: "Checkout", URL: "https://example.com/checkout"}
+	if msg := tabMessage(tab, false); strings.Contains(msg, "switch_tab") {
+		t.Errorf("message without WithTabSwitching mentions switch_tab: %q", msg)
+	}
+	if msg := tabMessage(tab, true); !strings.Contains(msg, "switch_tab") {
+		t.Errorf("message with WithTabSwitching does not offer switch_tab: %q", msg)
+	}
+}
@@ -0,0 +1,97 @@
+package computeruse
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// maxTags is the number of metadata pairs the API accepts per response
+const maxTags = 16
+
+// FindSessions returns the manifests of the sessions saved under dir (the
+// artifacts directory) carrying all of the given tags, newest first. Empty
+// tags match every session.
+func FindSessions(dir string, tags map[string]string) ([]Manifest, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*", "manifest.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing sessions: %w", err)
+	}
+	var sessions []Manifest
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading manifest: %w", err)
+		}
+		var m Manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", file, err)
+		}
+		if hasTags(m.Tags, tags) {
+			sessions = append(sessions, m)
+		}
+	}
+	// Session IDs start with a timestamp
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].SessionID > sessions[j].SessionID
+	})
+	return sessions, nil
+}
+
+// hasTags reports whether tags contains every pair of want
+func hasTags(tags, want map[string]string) bool {
+	for k, v := range want {
+		if tags[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// indexTemplate lists the sessions of an artifacts directory with links to their reports
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Sessions</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+td, th { padding: 0.3em 1em; text-align: left; }
+.tag { background: #eee; border-radius: 3px; padding: 0 0.4em; margin-right: 0.3em; }
+</style>
+</head>
+<body>
+<h1>Sessions</h1>
+<table>
+<tr><th>Session</th><th>Screenshots</th><th>Tags</th></tr>
+{{range .}}
+<tr>
+<td><a href="{{.SessionID}}/report.html">{{.SessionID}}</a></td>
+<td>{{len .Frames}}</td>
+<td>{{range $k, $v := .Tags}}<span class="tag">{{$k}}={{$v}}</span>{{end}}</td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// writeSessionIndex renders index.html listing every session under dir
+func writeSessionIndex(dir string) error {
+	sessions, err := FindSessions(dir, nil)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return fmt.Errorf("error creating session index: %w", err)
+	}
+	defer f.Close()
+	if err := indexTemplate.Execute(f, sessions); err != nil {
+		return fmt.Errorf("error writing session index: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// Task mixes scripted browser steps with prompt steps handled by the model, so
+// reliable boilerplate runs deterministically and only the fuzzy parts use the model
+type Task struct {
+	// URL is opened before the first step
+	URL   string     `json:"url"`
+	Steps []TaskStep `json:"steps"`
+}
+
+// TaskStep is one step of a Task. Exactly one of Goto, Click, Fill or Prompt is set.
+type TaskStep struct {
+	// Goto navigates to a URL
+	Goto string `json:"goto,omitempty"`
+	// Click clicks the element matching a CSS selector
+	Click string `json:"click,omitempty"`
+	// Fill replaces the value of the field matching a CSS selector with Value
+	Fill  string `json:"fill,omitempty"`
+	Value string `json:"value,omitempty"`
+	// Prompt hands control to the model until it answers or MaxTurns is reached
+	Prompt   string `json:"prompt,omitempty"`
+	MaxTurns int    `json:"max_turns,omitempty"`
+}
+
+// LoadTask reads a task definition from a JSON file
+func LoadTask(path string) (*Task, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading task: %w", err)
+	}
+	var task Task
+	if err := json.Unmarshal(data, &task); err != nil {
+		return nil, fmt.Errorf("error parsing task: %w", err)
+	}
+	for i, step := range task.Steps {
+		if err := step.validate(); err != nil {
+			return nil, fmt.Errorf("task step #%d: %w", i+1, err)
+		}
+	}
+	return &task, nil
+}
+
+func (s TaskStep) validate() error {
+	n := 0
+	for _, set := range []bool{s.Goto != "", s.Click != "", s.Fill != "", s.Prompt != ""} {
+		if set {
+			n++
+		}
+	}
+	if n != 1 {
+		return fmt.Errorf("exactly one of goto, click, fill or prompt must be set")
+	}
+	return nil
+}
+
+// RunTask opens the task URL in a new browser and executes the steps in order.
+// Prompt steps run with the given options and their results are returned in order;
+// a prompt step that does not complete stops the task.
+func RunTask(ctx context.Context, task *Task, maxTurns int, opts ...Option) ([]*Result, error) {
+	cfg := newConfig(opts)
+	browser, err := LaunchBrowser(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer browser.Close()
+	if err := browser.Open(ctx, task.URL); err != nil {
+		return nil, fmt.Errorf("error opening browser: %w", err)
+	}
+
+	var results []*Result
+	for i, step := range task.Steps {
+		if step.Prompt == "" {
+			cfg.events.notice(fmt.Sprintf("📜 Task step #%d", i+1))
+			if err := browser.runStep(ctx, step); err != nil {
+				return results, fmt.Errorf("task step #%d: %w", i+1, err)
+			}
+			continue
+		}
+
+		turns := step.MaxTurns
+		if turns <= 0 {
+			turns = maxTurns
+		}
+		result, err := Run(ctx, browser, step.Prompt, turns, opts...)
+		if result != nil {
+			results = append(results, result)
+		}
+		if err != nil {
+			return results, fmt.Errorf("task step #%d: %w", i+1, err)
+		}
+		if result.Status != StatusCompleted {
+			return results, fmt.Errorf("task step #%d ended with status %s", i+1, result.Status)
+		}
+	}
+	return results, nil
+}
+
+// runStep executes a scripted task step
+func (b *Browser) runStep(ctx context.Context, step TaskStep) error {
+	switch {
+	case step.Goto != "":
+		return b.Navigate(ctx, step.Goto)
+	case step.Click != "":
+		return b.ClickElement(ctx, step.Click)
+	case step.Fill != "":
+		return b.FillElement(ctx, step.Fill, step.Value)
+	}
+	return fmt.Errorf("not a scripted step")
+}
+
+// ClickElement clicks the element matching a CSS selector
+func (b *Browser) ClickElement(ctx context.Context, selector string) error {
+	return b.do(ctx, func(page *rod.Page) error {
+		el, err := page.Element(selector)
+		if err != nil {
+			return fmt.Errorf("error finding %s: %w", selector, err)
+		}
+		if err := el.Click(proto.InputMouseButtonLeft, 1); err != nil {
+			return fmt.Errorf("error clicking %s: %w", selector, err)
+		}
+		return settle(page, time.Second)
+	})
+}
+
+// FillElement replaces the value of the input field matching a CSS selector
+func (b *Browser) FillElement(ctx context.Context, selector, value string) error {
+	return b.do(ctx, func(page *rod.Page) error {
+		el, err := page.Element(selector)
+		if err != nil {
+			return fmt.Errorf("error finding %s: %w", selector, err)
+		}
+		if err := el.SelectAllText(); err != nil {
+			return fmt.Errorf("error selecting %s: %w", selector, err)
+		}
+		if err := el.Input(value); err != nil {
+			return fmt.Errorf("error filling %s: %w", selector, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,98 @@
+package computeruse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// PromptTemplate is a reusable instruction with named variables, e.g.
+// "find {{.product}} under {{.price}} on {{.site}}"
+type PromptTemplate struct {
+	Name string
+	tmpl *template.Template
+}
+
+// NewPromptTemplate parses an instruction template. Executing it fails when a
+// variable referenced by the template is not provided.
+func NewPromptTemplate(name, text string) (*PromptTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing prompt template %s: %w", name, err)
+	}
+	return &PromptTemplate{Name: name, tmpl: tmpl}, nil
+}
+
+// LoadPromptTemplate reads a template from a file, named after the file without its extension
+func LoadPromptTemplate(path string) (*PromptTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading prompt template: %w", err)
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return NewPromptTemplate(name, string(data))
+}
+
+// Render fills the template variables and returns the instruction.
+// vars is a map keyed by variable name or a struct whose fields match the template.
+func (t *PromptTemplate) Render(vars any) (string, error) {
+	var sb strings.Builder
+	if err := t.tmpl.Execute(&sb, vars); err != nil {
+		return "", fmt.Errorf("error rendering prompt template %s: %w", t.Name, err)
+	}
+	return sb.String(), nil
+}
+
+// PromptLibrary is a set of prompt templates looked up by name
+type PromptLibrary struct {
+	templates map[string]*PromptTemplate
+}
+
+// LoadPromptLibrary loads every *.tmpl file in dir
+func LoadPromptLibrary(dir string) (*PromptLibrary, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.tmpl"))
+	if err != nil {
+		return nil, fmt.Errorf("error listing prompt templates: %w", err)
+	}
+	lib := &PromptLibrary{templates: map[string]*PromptTemplate{}}
+	for _, path := range paths {
+		t, err := LoadPromptTemplate(path)
+		if err != nil {
+			return nil, err
+		}
+		lib.Add(t)
+	}
+	return lib, nil
+}
+
+// Add registers a template, replacing any template with the same name
+func (l *PromptLibrary) Add(t *PromptTemplate) {
+	if l.templates == nil {
+		l.templates = map[string]*PromptTemplate{}
+	}
+	l.templates[t.Name] = t
+}
+
+// Render fills the variables of the named template
+func (l *PromptLibrary) Render(name string, vars any) (string, error) {
+	t, ok := l.templates[name]
+	if !ok {
+		return "", fmt.Errorf("prompt template not found: %s", name)
+	}
+	return t.Render(vars)
+}
+
+// ParseTemplateVars parses "key=value" pairs as passed on the command line
+func ParseTemplateVars(pairs []string) (map[string]any, error) {
+	vars := map[string]any{}
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid template variable %q, expected key=value", pair)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
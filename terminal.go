@@ -0,0 +1,76 @@
+package computeruse
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// lineReader reads the lines of a terminal in a single goroutine feeding a
+// channel. Prompts share it, so a prompt given up on when its context is done
+// does not leave a reader behind racing the next prompt for its answer.
+type lineReader struct {
+	in    io.Reader
+	once  sync.Once
+	lines chan string
+	// abandoned is set when a prompt was given up on before its answer came
+	abandoned atomic.Bool
+}
+
+// lineReaders holds the lineReader of every input prompted on, e.g. os.Stdin
+var lineReaders sync.Map
+
+// terminalLines returns the lineReader shared by all prompts reading from in
+func terminalLines(in io.Reader) *lineReader {
+	r := &lineReader{in: in, lines: make(chan string, 1)}
+	if !reflect.TypeOf(in).Comparable() {
+		return r
+	}
+	shared, _ := lineReaders.LoadOrStore(in, r)
+	return shared.(*lineReader)
+}
+
+// readLine waits for the next line, trimmed of spaces. After a prompt was
+// given up on, a line already waiting is dropped, since it answered that
+// prompt; otherwise lines typed or piped ahead answer the prompts in order.
+func (r *lineReader) readLine(ctx context.Context) (string, error) {
+	r.once.Do(func() { go r.run() })
+	if r.abandoned.Swap(false) {
+		select {
+		case _, ok := <-r.lines:
+			if !ok {
+				return "", io.EOF
+			}
+		default:
+		}
+	}
+	select {
+	case line, ok := <-r.lines:
+		if !ok {
+			return "", io.EOF
+		}
+		return strings.TrimSpace(line), nil
+	case <-ctx.Done():
+		r.abandoned.Store(true)
+		return "", ctx.Err()
+	}
+}
+
+// run feeds the lines of in to r.lines until in is exhausted
+func (r *lineReader) run() {
+	defer close(r.lines)
+	reader := bufio.NewReader(r.in)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" || err == nil {
+			r.lines <- line
+		}
+		if err != nil {
+			return
+		}
+	}
+}
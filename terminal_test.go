@@ -0,0 +1,41 @@
+package computeruse
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestTerminalPromptsShareInput(t *testing.T) {
+	in, w := io.Pipe()
+	defer w.Close()
+	approve := TerminalApproval(in, io.Discard)
+	confirm := TerminalSafetyCheckHandler(in, io.Discard)
+
+	// A prompt given up on must not consume the answer to the next one
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if ok, _ := approve(ctx, ApprovalRequest{Class: "submit"}); ok {
+		t.Fatal("canceled approval was granted")
+	}
+
+	go w.Write([]byte("y\n"))
+	if !confirm(context.Background(), SafetyCheckRecord{}, &Action{Type: "click"}) {
+		t.Error("safety check was not acknowledged with y")
+	}
+	go w.Write([]byte("wrong page\n"))
+	if ok, reason := approve(context.Background(), ApprovalRequest{Class: "submit"}); ok || reason != "wrong page" {
+		t.Errorf("approval = %t, %q; want denial with the reason", ok, reason)
+	}
+}
+
+func TestTerminalPromptsReadBufferedInput(t *testing.T) {
+	in := strings.NewReader("y\ny\n")
+	confirm := TerminalSafetyCheckHandler(in, io.Discard)
+	for i := range 2 {
+		if !confirm(context.Background(), SafetyCheckRecord{}, &Action{Type: "click"}) {
+			t.Errorf("prompt %d was not acknowledged by its piped answer", i+1)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package computeruse
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Timeouts bounds the phases of an OpenAI API request. Connect failures
+// should fail fast while computer-use responses with long reasoning can take
+// minutes, so each phase has its own limit. Zero disables a limit.
+type Timeouts struct {
+	// Connect limits establishing the TCP connection
+	Connect time.Duration
+	// TLSHandshake limits the TLS handshake
+	TLSHandshake time.Duration
+	// ResponseHeader limits waiting for the response headers after the request is sent
+	ResponseHeader time.Duration
+	// Total limits the whole request including reading the body
+	Total time.Duration
+}
+
+// DefaultTimeouts are used when neither WithAPITimeouts nor the environment sets a limit
+var DefaultTimeouts = Timeouts{
+	Connect:        10 * time.Second,
+	TLSHandshake:   10 * time.Second,
+	ResponseHeader: 5 * time.Minute,
+	Total:          10 * time.Minute,
+}
+
+// TimeoutsFromEnv returns DefaultTimeouts overridden by OPENAI_CONNECT_TIMEOUT,
+// OPENAI_TLS_TIMEOUT, OPENAI_HEADER_TIMEOUT and OPENAI_REQUEST_TIMEOUT, which
+// take durations such as "5s" or "3m"
+func TimeoutsFromEnv() (Timeouts, error) {
+	t := DefaultTimeouts
+	for name, d := range map[string]*time.Duration{
+		"OPENAI_CONNECT_TIMEOUT": &t.Connect,
+		"OPENAI_TLS_TIMEOUT":     &t.TLSHandshake,
+		"OPENAI_HEADER_TIMEOUT":  &t.ResponseHeader,
+		"OPENAI_REQUEST_TIMEOUT": &t.Total,
+	} {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(v)
+		if err != nil {
+			return t, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*d = parsed
+	}
+	return t, nil
+}
+
+// httpClient returns an HTTP client enforcing the timeouts
+func (t Timeouts) httpClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{Timeout: t.Connect, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = t.TLSHandshake
+	transport.ResponseHeaderTimeout = t.ResponseHeader
+	return &http.Client{Transport: transport, Timeout: t.Total}
+}
+
+var (
+	defaultClientOnce sync.Once
+	defaultClient     *http.Client
+)
+
+// defaultHTTPClient returns the client used for API calls without WithAPITimeouts,
+// configured from the environment. Invalid values fall back to DefaultTimeouts;
+// runs report them through newConfig before getting here.
+func defaultHTTPClient() *http.Client {
+	defaultClientOnce.Do(func() {
+		t, err := TimeoutsFromEnv()
+		if err != nil {
+			t = DefaultTimeouts
+		}
+		defaultClient = t.httpClient()
+	})
+	return defaultClient
+}
@@ -0,0 +1,16 @@
+package computeruse
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestInvalidTimeoutEnvIsReported(t *testing.T) {
+	t.Setenv("OPENAI_CONNECT_TIMEOUT", "soon")
+	if err := errors.Join(newConfig(nil).errs...); err == nil {
+		t.Error("invalid OPENAI_CONNECT_TIMEOUT was not reported")
+	}
+	if err := errors.Join(newConfig([]Option{WithAPITimeouts(DefaultTimeouts)}).errs...); err != nil {
+		t.Errorf("WithAPITimeouts should override the environment, got %v", err)
+	}
+}
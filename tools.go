@@ -0,0 +1,146 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// functionTool is a function tool offered to the model next to the computer tool
+type functionTool struct {
+	tool Tool
+	call func(ctx context.Context, c Computer, arguments string) (string, error)
+}
+
+// callFunctionTool runs the function tool requested by a function_call output
+// item, bounded by timeout like a computer action
+func callFunctionTool(ctx context.Context, tools []functionTool, c Computer, name, arguments string, timeout time.Duration) (string, error) {
+	for _, t := range tools {
+		if t.tool.Name == name {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return t.call(ctx, c, arguments)
+		}
+	}
+	return "", fmt.Errorf("unknown function tool: %s", name)
+}
+
+// scrollUntilTool scrolls the page until a condition is met in one step
+var scrollUntilTool = functionTool{
+	tool: Tool{
+		Type: "function",
+		Name: "scroll_until",
+		Description: "Scroll down the page (e.g. an infinite list) until at least min_items elements match selector, " +
+			"the given text is visible, or the bottom of the page is reached. Use this instead of scrolling one screen at a time.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"selector":    map[string]any{"type": "string", "description": "CSS selector of the list items to count"},
+				"min_items":   map[string]any{"type": "integer", "description": "Stop once this many items match selector"},
+				"text":        map[string]any{"type": "string", "description": "Stop once this text appears on the page"},
+				"max_scrolls": map[string]any{"type": "integer", "description": "Maximum number of scroll steps (default 20, at most 50)"},
+			},
+		},
+	},
+	call: func(ctx context.Context, c Computer, arguments string) (string, error) {
+		b, ok := c.(*Browser)
+		if !ok {
+			return "", fmt.Errorf("scroll_until is only available in the browser environment")
+		}
+		var cond ScrollCondition
+		if err := json.Unmarshal([]byte(arguments), &cond); err != nil {
+			return "", fmt.Errorf("invalid scroll_until arguments: %w", err)
+		}
+		result, err := b.ScrollUntil(ctx, cond)
+		if err != nil {
+			return "", err
+		}
+		out, err := json.Marshal(result)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal scroll_until result: %w", err)
+		}
+		return string(out), nil
+	},
+}
+
+// switchTabTool lets the model move between the tabs of the browser, e.g.
+// back to the original page after a popup
+var switchTabTool = functionTool{
+	tool: Tool{
+		Type:        "function",
+		Name:        "switch_tab",
+		Description: "Switch to another browser tab. Tabs are numbered from 1 in the order they were opened.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"tab": map[string]any{"type": "integer", "description": "Index of the tab to switch to"},
+			},
+			"required": []string{"tab"},
+		},
+	},
+	call: func(ctx context.Context, c Computer, arguments string) (string, error) {
+		var args struct {
+			Tab int `json:"tab"`
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid switch_tab arguments: %w", err)
+		}
+		action, err := NewSwitchTab(args.Tab)
+		if err != nil {
+			return "", err
+		}
+		if err := performAction(ctx, c, &action); err != nil {
+			return "", err
+		}
+		b, ok := c.(*Browser)
+		if !ok {
+			return fmt.Sprintf("switched to tab %d", args.Tab), nil
+		}
+		tabs, err := b.Tabs(ctx)
+		if err != nil {
+			return "", err
+		}
+		out, err := json.Marshal(tabs)
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal tabs: %w", err)
+		}
+		return fmt.Sprintf("switched to tab %d; take a screenshot to see it. Open tabs: %s", args.Tab, out), nil
+	},
+}
+
+// resizeViewportTool lets the model change the browser viewport, e.g. to reveal
+// a desktop-only layout. The computer tool's display size follows from the next turn.
+var resizeViewportTool = functionTool{
+	tool: Tool{
+		Type: "function",
+		Name: "resize_viewport",
+		Description: "Resize the browser viewport, e.g. to a wider size that reveals the desktop layout of a page. " +
+			"Take a screenshot afterwards to see the new layout.",
+		Parameters: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"width":  map[string]any{"type": "integer", "description": "New viewport width in pixels"},
+				"height": map[string]any{"type": "integer", "description": "New viewport height in pixels"},
+			},
+			"required": []string{"width", "height"},
+		},
+	},
+	call: func(ctx context.Context, c Computer, arguments string) (string, error) {
+		var args struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		}
+		if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+			return "", fmt.Errorf("invalid resize_viewport arguments: %w", err)
+		}
+		action, err := NewResize(args.Width, args.Height)
+		if err != nil {
+			return "", err
+		}
+		if err := performAction(ctx, c, &action); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("viewport resized to %dx%d", args.Width, args.Height), nil
+	},
+}
@@ -0,0 +1,22 @@
+package computeruse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCallFunctionToolTimeout(t *testing.T) {
+	slow := functionTool{
+		tool: Tool{Type: "function", Name: "slow"},
+		call: func(ctx context.Context, c Computer, arguments string) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	}
+	_, err := callFunctionTool(context.Background(), []functionTool{slow}, nil, "slow", "{}", 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the action timeout", err)
+	}
+}
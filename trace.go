@@ -0,0 +1,260 @@
+package computeruse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Trace is the record of a run written by a TraceRecorder to trace.json
+type Trace struct {
+	SessionID   string `json:"session_id"`
+	Instruction string `json:"instruction"`
+	StartURL    string `json:"start_url,omitempty"`
+	// Width and Height are the screen size the actions were executed on
+	Width   int         `json:"width"`
+	Height  int         `json:"height"`
+	Started time.Time   `json:"started"`
+	Steps   []TraceStep `json:"steps"`
+	Usage   UsageInfo   `json:"usage"`
+	Status  Status      `json:"status,omitempty"`
+	Output  string      `json:"output,omitempty"`
+}
+
+// TraceStep is an executed action. Action is as the model sent it and
+// ScreenAction in screen coordinates, which is what ReplayTrace executes.
+type TraceStep struct {
+	Turn         int       `json:"turn"`
+	Time         time.Time `json:"time"`
+	Action       Action    `json:"action"`
+	ScreenAction Action    `json:"screen_action"`
+	// Screenshot is the file name, within the trace directory, of the screen after the action
+	Screenshot string `json:"screenshot,omitempty"`
+	URL        string `json:"url,omitempty"`
+	// Function is set on "function_call" steps, which ReplayTrace skips
+	Function *FunctionCallRecord `json:"function,omitempty"`
+	Error    string              `json:"error,omitempty"`
+}
+
+// TraceRecorder writes a replayable trace of every run it is attached to with
+// WithTraceRecorder: a directory per session holding the request and response
+// JSON of each turn, the screenshots, and trace.json with the executed actions,
+// token usage and final output
+type TraceRecorder struct {
+	mu    sync.Mutex
+	root  string
+	dir   string
+	turn  int
+	trace Trace
+}
+
+// NewTraceRecorder creates a recorder writing traces under dir
+func NewTraceRecorder(dir string) *TraceRecorder {
+	return &TraceRecorder{root: dir}
+}
+
+// start begins the trace of a session. Errors are reported through the
+// returned error only, so tracing never stops a run.
+func (r *TraceRecorder) start(sessionID, instruction string, c Computer) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	width, height := c.Dimensions()
+	r.dir = filepath.Join(r.root, sessionID)
+	r.turn = 0
+	r.trace = Trace{SessionID: sessionID, Instruction: instruction, Width: width, Height: height, Started: time.Now()}
+	if u, ok := c.(urlReporter); ok {
+		r.trace.StartURL = u.GetCurrentUrl()
+	}
+	if err := os.MkdirAll(r.dir, 0755); err != nil {
+		return fmt.Errorf("error creating trace directory: %w", err)
+	}
+	return r.save()
+}
+
+// request begins a turn, which keeps counting across retries, and saves its request
+func (r *TraceRecorder) request(request Request) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	r.turn++
+	turn := r.turn
+	r.mu.Unlock()
+	return r.writeJSON(fmt.Sprintf("%03d-request.json", turn), request)
+}
+
+// response saves the response of the current turn and adds its token usage
+func (r *TraceRecorder) response(response *Response) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	r.trace.Usage = r.trace.Usage.add(response.Usage)
+	turn := r.turn
+	r.mu.Unlock()
+	return r.writeJSON(fmt.Sprintf("%03d-response.json", turn), response)
+}
+
+// action records an executed action with the screenshot taken after it
+func (r *TraceRecorder) action(action, screenAction Action, out *ComputerOutput, actionErr error) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	turn := r.turn
+	step := TraceStep{Turn: turn, Time: time.Now(), Action: action, ScreenAction: screenAction, URL: out.CurrentURL}
+	if actionErr != nil {
+		step.Error = actionErr.Error()
+	}
+	if data, err := decodeDataURL(out.ImageURL); err == nil {
+		step.Screenshot = fmt.Sprintf("%03d-%02d-%s.png", turn, len(r.trace.Steps)+1, action.Type)
+		if err := os.WriteFile(filepath.Join(r.dir, step.Screenshot), data, 0644); err != nil {
+			return fmt.Errorf("error saving trace screenshot: %w", err)
+		}
+	}
+	r.trace.Steps = append(r.trace.Steps, step)
+	return r.save()
+}
+
+// function records a call of a function tool
+func (r *TraceRecorder) function(call FunctionCallRecord, callErr error) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	step := TraceStep{Turn: r.turn, Time: time.Now(), Action: Action{Type: "function_call"}, Function: &call}
+	if callErr != nil {
+		step.Error = callErr.Error()
+	}
+	r.trace.Steps = append(r.trace.Steps, step)
+	return r.save()
+}
+
+// finish records the outcome of the run
+func (r *TraceRecorder) finish(result *Result) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.trace.Status = result.Status
+	r.trace.Output = result.Output
+	return r.save()
+}
+
+func (r *TraceRecorder) writeJSON(name string, v any) error {
+	r.mu.Lock()
+	dir := r.dir
+	r.mu.Unlock()
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %w", name, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		return fmt.Errorf("error saving %s: %w", name, err)
+	}
+	return nil
+}
+
+// save rewrites trace.json; r.mu must be held
+func (r *TraceRecorder) save() error {
+	data, err := json.MarshalIndent(r.trace, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding trace: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(r.dir, "trace.json"), data, 0644); err != nil {
+		return fmt.Errorf("error saving trace: %w", err)
+	}
+	return nil
+}
+
+// LoadTrace reads the trace.json of a trace directory
+func LoadTrace(dir string) (*Trace, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "trace.json"))
+	if err != nil {
+		return nil, fmt.Errorf("error reading trace: %w", err)
+	}
+	var t Trace
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("error decoding trace: %w", err)
+	}
+	return &t, nil
+}
+
+// ReplayStep is the outcome of replaying a TraceStep
+type ReplayStep struct {
+	Action Action
+	Output *ComputerOutput
+	// Diff is the fraction of pixels that differ from the recorded screenshot,
+	// or -1 when there is no recorded screenshot to compare with
+	Diff float64
+}
+
+// ReplayTrace re-executes the actions recorded in the trace directory against
+// c without calling the API, starting from the trace's start URL when c can
+// navigate. Each new screenshot is compared with the recorded one, so a
+// regression shows up as a large Diff. Actions that failed when recorded are skipped.
+func ReplayTrace(ctx context.Context, c Computer, dir string, opts ...Option) ([]ReplayStep, error) {
+	t, err := LoadTrace(dir)
+	if err != nil {
+		return nil, err
+	}
+	if width, height := c.Dimensions(); width != t.Width || height != t.Height {
+		r, ok := c.(resizer)
+		if !ok {
+			return nil, fmt.Errorf("trace was recorded on a %dx%d screen, got %dx%d", t.Width, t.Height, width, height)
+		}
+		if err := r.Resize(ctx, t.Width, t.Height); err != nil {
+			return nil, err
+		}
+	}
+	if n, ok := c.(navigator); ok && t.StartURL != "" {
+		if err := n.Navigate(ctx, t.StartURL); err != nil {
+			return nil, err
+		}
+	}
+
+	cfg := newConfig(opts)
+	var steps []TraceStep
+	var actions []Action
+	for _, step := range t.Steps {
+		if step.Error == "" && step.Function == nil {
+			steps = append(steps, step)
+			actions = append(actions, step.ScreenAction)
+		}
+	}
+	outputs, err := runActions(ctx, c, actions, cfg)
+	replayed := make([]ReplayStep, len(outputs))
+	for i, out := range outputs {
+		replayed[i] = ReplayStep{Action: actions[i], Output: out, Diff: -1}
+		if steps[i].Screenshot == "" {
+			continue
+		}
+		recorded, rerr := os.ReadFile(filepath.Join(dir, steps[i].Screenshot))
+		current, derr := decodeDataURL(out.ImageURL)
+		if rerr != nil || derr != nil {
+			continue
+		}
+		// Recorded screenshots are at the size the model saw
+		if rc, err := png.DecodeConfig(bytes.NewReader(recorded)); err == nil {
+			if current, err = newScreenScale(t.Width, t.Height, rc.Width, rc.Height).shrink(current); err != nil {
+				continue
+			}
+		}
+		if diff, err := pixelDiff(recorded, current); err == nil {
+			replayed[i].Diff = diff
+		}
+	}
+	return replayed, err
+}
@@ -0,0 +1,142 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-rod/rod"
+	"github.com/go-rod/rod/lib/proto"
+)
+
+// EnableFileChooser intercepts file chooser dialogs, which a headless browser
+// cannot show, so SetInputFiles can answer them. Call it after Open; tabs
+// opened later are intercepted as well.
+func (b *Browser) EnableFileChooser(ctx context.Context) error {
+	b.interceptChooser = true
+	for _, tab := range b.tabs {
+		if err := b.interceptFileChooser(tab.Context(ctx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// interceptFileChooser records the file choosers opened by page
+func (b *Browser) interceptFileChooser(page *rod.Page) error {
+	if err := (proto.PageSetInterceptFileChooserDialog{Enabled: true}).Call(page); err != nil {
+		return fmt.Errorf("error intercepting file choosers: %w", err)
+	}
+	go page.Context(context.Background()).EachEvent(func(e *proto.PageFileChooserOpened) {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.chooser = e
+	})()
+	return nil
+}
+
+// FileChooserOpen reports whether the page is waiting for files to be chosen
+func (b *Browser) FileChooserOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.chooser != nil
+}
+
+// SetInputFiles answers the open file chooser with paths, or, when none is
+// open, sets them on the first file input of the page
+func (b *Browser) SetInputFiles(ctx context.Context, paths []string) error {
+	b.mu.Lock()
+	chooser := b.chooser
+	b.chooser = nil
+	b.mu.Unlock()
+
+	err := b.do(ctx, func(page *rod.Page) error {
+		if chooser != nil {
+			return proto.DOMSetFileInputFiles{Files: paths, BackendNodeID: chooser.BackendNodeID}.Call(page)
+		}
+		inputs, err := page.Elements("input[type=file]")
+		if err != nil {
+			return err
+		}
+		if len(inputs) == 0 {
+			return fmt.Errorf("no file chooser is open and the page has no file input")
+		}
+		return inputs.First().SetFiles(paths)
+	})
+	if err != nil {
+		return fmt.Errorf("error attaching files: %w", err)
+	}
+	return nil
+}
+
+// attachFileTool lets the model pick one of the files offered with
+// WithUploadFiles by name. Paths never reach the model.
+func attachFileTool(files map[string]string) functionTool {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return functionTool{
+		tool: Tool{
+			Type: "function",
+			Name: "attach_file",
+			Description: "Attach a file to the open file chooser, or to the file input of the page. " +
+				"Click the upload button or file field first. Available files: " + strings.Join(names, ", "),
+			Parameters: map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"name": map[string]any{"type": "string", "enum": names},
+				},
+				"required": []string{"name"},
+			},
+		},
+		call: func(ctx context.Context, c Computer, arguments string) (string, error) {
+			var args struct {
+				Name string `json:"name"`
+			}
+			if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+				return "", fmt.Errorf("invalid attach_file arguments: %w", err)
+			}
+			b, ok := c.(*Browser)
+			if !ok {
+				return "", fmt.Errorf("attach_file is only available in the browser environment")
+			}
+			path, ok := files[args.Name]
+			if !ok {
+				return "", fmt.Errorf("unknown file %q, choose one of: %s", args.Name, strings.Join(names, ", "))
+			}
+			if err := b.SetInputFiles(ctx, []string{path}); err != nil {
+				return "", err
+			}
+			return "attached " + args.Name, nil
+		},
+	}
+}
+
+// uploadFileNames maps the base names of paths to the paths, adding a numeric
+// suffix to names that repeat
+func uploadFileNames(paths []string) map[string]string {
+	files := make(map[string]string, len(paths))
+	for _, p := range paths {
+		name := filepath.Base(p)
+		for n := 2; files[name] != ""; n++ {
+			name = fmt.Sprintf("%d-%s", n, filepath.Base(p))
+		}
+		files[name] = p
+	}
+	return files
+}
+
+// fileChooserMessage tells the model which files it can attach to an open chooser
+func fileChooserMessage(files map[string]string) string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "A file chooser is open. Call attach_file with one of: " + strings.Join(names, ", ")
+}
@@ -0,0 +1,82 @@
+package computeruse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Verdicts of an answer verification
+const (
+	VerdictCorrect   = "correct"
+	VerdictIncorrect = "incorrect"
+	VerdictUncertain = "uncertain"
+)
+
+// DefaultVerificationRubric is used by WithAnswerVerification when no rubric is given
+const DefaultVerificationRubric = "The answer is correct only if it fully answers the task and is supported by what the screenshot shows. " +
+	"Answer uncertain when the screenshot does not show enough to decide."
+
+// Verification is a judge model's verdict on the final answer of a run
+type Verification struct {
+	Model      string  `json:"model"`
+	Verdict    string  `json:"verdict"`
+	Confidence float64 `json:"confidence"`
+	Reason     string  `json:"reason"`
+}
+
+// verificationSchema is the structured output format of the judge model
+var verificationSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"verdict":    map[string]any{"type": "string", "enum": []string{VerdictCorrect, VerdictIncorrect, VerdictUncertain}},
+		"confidence": map[string]any{"type": "number"},
+		"reason":     map[string]any{"type": "string"},
+	},
+	"required":             []string{"verdict", "confidence", "reason"},
+	"additionalProperties": false,
+}
+
+// verifyAnswer sends the final screenshot and answer to the judge model and returns its verdict
+func verifyAnswer(ctx context.Context, cfg *config, instruction string, result *Result) (*Verification, error) {
+	parts := []ContentPart{{
+		Type: "input_text",
+		Text: fmt.Sprintf("You verify answers given by a browsing agent.\nRubric: %s\n\nTask: %s\n\nAnswer: %s\n\n"+
+			"The screenshot shows the screen when the agent answered. Give your verdict and a confidence between 0 and 1.",
+			cfg.verifyRubric, instruction, result.Output),
+	}}
+	if result.FinalScreenshot != "" {
+		data, err := os.ReadFile(result.FinalScreenshot)
+		if err != nil {
+			return nil, fmt.Errorf("error reading final screenshot: %w", err)
+		}
+		parts = append(parts, ContentPart{Type: "input_image", ImageURL: dataURL(data)})
+	}
+
+	response, err := createResponse(ctx, cfg, Request{
+		Model: cfg.verifyModel,
+		Input: []Input{{Role: "user", Content: parts}},
+		Text: &Text{Format: Format{
+			Type:   "json_schema",
+			Name:   "verification",
+			Strict: true,
+			Schema: verificationSchema,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error calling judge model: %w", err)
+	}
+
+	for _, o := range response.Output {
+		if o.Type != "message" {
+			continue
+		}
+		v := &Verification{Model: cfg.verifyModel}
+		if err := json.Unmarshal([]byte(extractJSON(o.Text())), v); err != nil {
+			return nil, fmt.Errorf("error decoding verdict: %w", err)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("judge model returned no verdict")
+}
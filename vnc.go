@@ -0,0 +1,403 @@
+package computeruse
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/des"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"math/bits"
+	"net"
+	"sync"
+	"time"
+)
+
+// vncDriver speaks the RFB protocol to a VNC server, e.g. a desktop container
+type vncDriver struct {
+	mu    sync.Mutex
+	conn  net.Conn
+	r     *bufio.Reader
+	frame *image.RGBA
+	// broken is the read error that left an update half read. The rest of it
+	// would be parsed as new messages, so the connection is closed for good.
+	broken error
+}
+
+// NewVNCDesktop connects to a VNC server at addr ("host:5900") and returns a
+// Desktop operating it. password may be empty for servers without authentication.
+func NewVNCDesktop(ctx context.Context, addr, password string) (*Desktop, error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to VNC server: %w", err)
+	}
+	v := &vncDriver{conn: conn, r: bufio.NewReader(conn)}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if err := v.handshake(password); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error during VNC handshake: %w", err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	d, err := newDesktop(ctx, v)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+func (v *vncDriver) environment() string {
+	return "ubuntu"
+}
+
+// Close closes the connection to the VNC server
+func (v *vncDriver) Close() error {
+	return v.conn.Close()
+}
+
+// handshake negotiates protocol version 3.8, authentication and a 32-bit true-colour pixel format
+func (v *vncDriver) handshake(password string) error {
+	version := make([]byte, 12)
+	if _, err := io.ReadFull(v.r, version); err != nil {
+		return err
+	}
+	if _, err := v.conn.Write([]byte("RFB 003.008\n")); err != nil {
+		return err
+	}
+
+	count, err := v.r.ReadByte()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return v.readReason()
+	}
+	types := make([]byte, count)
+	if _, err := io.ReadFull(v.r, types); err != nil {
+		return err
+	}
+	security := byte(0)
+	for _, t := range types {
+		if t == 1 || (t == 2 && password != "") {
+			security = t
+			if t == 2 {
+				break
+			}
+		}
+	}
+	if security == 0 {
+		return fmt.Errorf("no supported security type offered: %v", types)
+	}
+	if _, err := v.conn.Write([]byte{security}); err != nil {
+		return err
+	}
+	if security == 2 {
+		challenge := make([]byte, 16)
+		if _, err := io.ReadFull(v.r, challenge); err != nil {
+			return err
+		}
+		response, err := vncAuthResponse(password, challenge)
+		if err != nil {
+			return err
+		}
+		if _, err := v.conn.Write(response); err != nil {
+			return err
+		}
+	}
+	var result uint32
+	if err := binary.Read(v.r, binary.BigEndian, &result); err != nil {
+		return err
+	}
+	if result != 0 {
+		return v.readReason()
+	}
+
+	// ClientInit: share the desktop with other clients
+	if _, err := v.conn.Write([]byte{1}); err != nil {
+		return err
+	}
+	var init struct {
+		Width, Height uint16
+		PixelFormat   [16]byte
+		NameLength    uint32
+	}
+	if err := binary.Read(v.r, binary.BigEndian, &init); err != nil {
+		return err
+	}
+	if _, err := v.r.Discard(int(init.NameLength)); err != nil {
+		return err
+	}
+	v.frame = image.NewRGBA(image.Rect(0, 0, int(init.Width), int(init.Height)))
+
+	// SetPixelFormat: 32 bpp, depth 24, little endian, true colour, RGB shifts 0/8/16
+	setPixelFormat := []byte{0, 0, 0, 0, 32, 24, 0, 1, 0, 255, 0, 255, 0, 255, 0, 8, 16, 0, 0, 0}
+	// SetEncodings: raw only
+	setEncodings := []byte{2, 0, 0, 1, 0, 0, 0, 0}
+	_, err = v.conn.Write(append(setPixelFormat, setEncodings...))
+	return err
+}
+
+// readReason reads the failure reason string sent by the server
+func (v *vncDriver) readReason() error {
+	var n uint32
+	if err := binary.Read(v.r, binary.BigEndian, &n); err != nil {
+		return err
+	}
+	reason := make([]byte, n)
+	if _, err := io.ReadFull(v.r, reason); err != nil {
+		return err
+	}
+	return fmt.Errorf("VNC server refused connection: %s", reason)
+}
+
+// vncAuthResponse encrypts the challenge with the password using VNC's DES variant,
+// which reverses the bits of each key byte
+func vncAuthResponse(password string, challenge []byte) ([]byte, error) {
+	key := make([]byte, 8)
+	copy(key, password)
+	for i, b := range key {
+		key[i] = bits.Reverse8(b)
+	}
+	cipher, err := des.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	response := make([]byte, 16)
+	cipher.Encrypt(response[:8], challenge[:8])
+	cipher.Encrypt(response[8:], challenge[8:])
+	return response, nil
+}
+
+func (v *vncDriver) screenshot(ctx context.Context) ([]byte, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.broken != nil {
+		return nil, fmt.Errorf("VNC connection is broken: %w", v.broken)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		v.conn.SetDeadline(deadline)
+		defer v.conn.SetDeadline(time.Time{})
+	}
+
+	// FramebufferUpdateRequest for the whole screen, non-incremental
+	b := v.frame.Bounds()
+	req := []byte{3, 0, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(req[6:], uint16(b.Dx()))
+	binary.BigEndian.PutUint16(req[8:], uint16(b.Dy()))
+	if _, err := v.conn.Write(req); err != nil {
+		return nil, fmt.Errorf("error requesting framebuffer: %w", err)
+	}
+	if err := v.readFramebufferUpdate(); err != nil {
+		v.broken = err
+		v.conn.Close()
+		return nil, fmt.Errorf("error reading framebuffer: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, v.frame); err != nil {
+		return nil, fmt.Errorf("error encoding screenshot: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// readFramebufferUpdate reads server messages until a framebuffer update has been applied
+func (v *vncDriver) readFramebufferUpdate() error {
+	for {
+		msgType, err := v.r.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch msgType {
+		case 0: // FramebufferUpdate
+			var header struct {
+				Padding uint8
+				Rects   uint16
+			}
+			if err := binary.Read(v.r, binary.BigEndian, &header); err != nil {
+				return err
+			}
+			for i := 0; i < int(header.Rects); i++ {
+				if err := v.readRect(); err != nil {
+					return err
+				}
+			}
+			return nil
+		case 1: // SetColourMapEntries, unused with true colour
+			var header struct {
+				Padding    uint8
+				FirstColor uint16
+				Colors     uint16
+			}
+			if err := binary.Read(v.r, binary.BigEndian, &header); err != nil {
+				return err
+			}
+			if _, err := v.r.Discard(int(header.Colors) * 6); err != nil {
+				return err
+			}
+		case 2: // Bell
+		case 3: // ServerCutText
+			var header struct {
+				Padding [3]byte
+				Length  uint32
+			}
+			if err := binary.Read(v.r, binary.BigEndian, &header); err != nil {
+				return err
+			}
+			if _, err := v.r.Discard(int(header.Length)); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unsupported server message type %d", msgType)
+		}
+	}
+}
+
+// readRect reads one raw-encoded rectangle into the framebuffer. The part of
+// the rectangle outside the framebuffer, e.g. after the desktop was resized,
+// is read and discarded.
+func (v *vncDriver) readRect() error {
+	var rect struct {
+		X, Y, Width, Height uint16
+		Encoding            int32
+	}
+	if err := binary.Read(v.r, binary.BigEndian, &rect); err != nil {
+		return err
+	}
+	if rect.Encoding != 0 {
+		return fmt.Errorf("unsupported encoding %d", rect.Encoding)
+	}
+	bounds := v.frame.Bounds()
+	visible := image.Rect(int(rect.X), int(rect.Y), int(rect.X)+int(rect.Width), int(rect.Y)+int(rect.Height)).Intersect(bounds)
+	row := make([]byte, int(rect.Width)*4)
+	for y := 0; y < int(rect.Height); y++ {
+		if _, err := io.ReadFull(v.r, row); err != nil {
+			return err
+		}
+		py := int(rect.Y) + y
+		if py < visible.Min.Y || py >= visible.Max.Y {
+			continue
+		}
+		offset := v.frame.PixOffset(visible.Min.X, py)
+		pixels := row[(visible.Min.X-int(rect.X))*4:]
+		for x := 0; x < visible.Dx(); x++ {
+			copy(v.frame.Pix[offset+x*4:offset+x*4+3], pixels[x*4:x*4+3])
+			v.frame.Pix[offset+x*4+3] = 255
+		}
+	}
+	return nil
+}
+
+// pointer sends a PointerEvent with the given button mask
+func (v *vncDriver) pointer(x, y int, mask uint8) error {
+	msg := []byte{5, mask, 0, 0, 0, 0}
+	binary.BigEndian.PutUint16(msg[2:], uint16(x))
+	binary.BigEndian.PutUint16(msg[4:], uint16(y))
+	_, err := v.conn.Write(msg)
+	return err
+}
+
+// key sends a KeyEvent for a keysym
+func (v *vncDriver) key(keysym uint32, down bool) error {
+	msg := []byte{4, 0, 0, 0, 0, 0, 0, 0}
+	if down {
+		msg[1] = 1
+	}
+	binary.BigEndian.PutUint32(msg[4:], keysym)
+	_, err := v.conn.Write(msg)
+	return err
+}
+
+func (v *vncDriver) move(ctx context.Context, x, y int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.pointer(x, y, 0)
+}
+
+func (v *vncDriver) click(ctx context.Context, x, y int, button string, count int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	mask := uint8(1)
+	if button == "right" {
+		mask = 4
+	}
+	for i := 0; i < count; i++ {
+		if err := v.pointer(x, y, mask); err != nil {
+			return err
+		}
+		if err := v.pointer(x, y, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *vncDriver) scroll(ctx context.Context, x, y, scrollX, scrollY int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, s := range scrollSteps(scrollX, scrollY) {
+		mask := uint8(1) << (s.button - 1)
+		for i := 0; i < s.count; i++ {
+			if err := v.pointer(x, y, mask); err != nil {
+				return err
+			}
+			if err := v.pointer(x, y, 0); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (v *vncDriver) typeText(ctx context.Context, text string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, r := range text {
+		_, code, err := x11Keysym(string(r))
+		if r == '\n' {
+			code, err = 0xff0d, nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := v.key(code, true); err != nil {
+			return err
+		}
+		if err := v.key(code, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (v *vncDriver) pressKeys(ctx context.Context, keys []string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	codes := make([]uint32, 0, len(keys))
+	for _, key := range keys {
+		_, code, err := x11Keysym(key)
+		if err != nil {
+			return err
+		}
+		codes = append(codes, code)
+	}
+	// Press the chord in order and release it in reverse
+	for _, code := range codes {
+		if err := v.key(code, true); err != nil {
+			return err
+		}
+	}
+	for i := len(codes) - 1; i >= 0; i-- {
+		if err := v.key(codes[i], false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package computeruse
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"image"
+	"testing"
+)
+
+func TestReadRectClipsToFramebuffer(t *testing.T) {
+	var msg bytes.Buffer
+	binary.Write(&msg, binary.BigEndian, struct {
+		X, Y, Width, Height uint16
+		Encoding            int32
+	}{X: 2, Y: 2, Width: 4, Height: 4})
+	msg.Write(bytes.Repeat([]byte{0x10, 0x20, 0x30, 0}, 16))
+	msg.WriteByte(0xAB) // the next message must be left unread
+
+	v := &vncDriver{r: bufio.NewReader(&msg), frame: image.NewRGBA(image.Rect(0, 0, 4, 4))}
+	if err := v.readRect(); err != nil {
+		t.Fatal(err)
+	}
+	if got := v.frame.RGBAAt(3, 3); got.R != 0x10 || got.A != 255 {
+		t.Errorf("pixel inside the rectangle = %v, want it drawn", got)
+	}
+	if got := v.frame.RGBAAt(1, 1); got.A != 0 {
+		t.Errorf("pixel outside the rectangle = %v, want it untouched", got)
+	}
+	if next, err := v.r.ReadByte(); err != nil || next != 0xAB {
+		t.Errorf("next byte = %#x, %v; want the clipped pixels discarded", next, err)
+	}
+}
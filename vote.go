@@ -0,0 +1,92 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// VoteResult aggregates the final answers of parallel sessions
+type VoteResult struct {
+	// Answer is the winning answer, as given by the first session that produced it
+	Answer string `json:"answer"`
+	// Votes is the number of sessions that agreed with Answer
+	Votes int `json:"votes"`
+	// Agreement is Votes divided by the number of sessions that produced an answer
+	Agreement float64 `json:"agreement"`
+	// Results holds the result of every session, nil for sessions that failed to start
+	Results []*Result `json:"results"`
+	// Errors holds the error of every session, nil for sessions that succeeded
+	Errors []error `json:"-"`
+}
+
+// Judge picks the final answer from the answers of the sessions that completed
+type Judge func(ctx context.Context, instruction string, answers []string) (string, error)
+
+// SampleAnswers runs the same task in k parallel sessions, each in its own
+// browser, and aggregates the final answers. With a nil judge the answer given
+// by most sessions wins, compared after trimming spaces and ignoring case.
+// Per-session files, downloads, traces and memory are kept apart as in Pool.RunAll.
+func SampleAnswers(ctx context.Context, url, instruction string, maxTurns, k int, judge Judge, opts ...Option) (*VoteResult, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("at least one session is required, got %d", k)
+	}
+	vote := &VoteResult{Results: make([]*Result, k), Errors: make([]error, k)}
+	var wg sync.WaitGroup
+	for i := range k {
+		sessionOpts := append(opts[:len(opts):len(opts)], isolateSession(i))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vote.Results[i], vote.Errors[i] = BrowserUseResult(ctx, url, instruction, maxTurns, sessionOpts...)
+		}()
+	}
+	wg.Wait()
+
+	var answers []string
+	for i, r := range vote.Results {
+		if vote.Errors[i] == nil && r != nil && r.Status == StatusCompleted && r.Output != "" {
+			answers = append(answers, r.Output)
+		}
+	}
+	if len(answers) == 0 {
+		return vote, fmt.Errorf("none of the %d sessions produced an answer", k)
+	}
+
+	if judge != nil {
+		answer, err := judge(ctx, instruction, answers)
+		if err != nil {
+			return vote, fmt.Errorf("error judging answers: %w", err)
+		}
+		vote.Answer = answer
+		for _, a := range answers {
+			if normalizeAnswer(a) == normalizeAnswer(answer) {
+				vote.Votes++
+			}
+		}
+	} else {
+		vote.Answer, vote.Votes = majorityAnswer(answers)
+	}
+	vote.Agreement = float64(vote.Votes) / float64(len(answers))
+	return vote, nil
+}
+
+// majorityAnswer returns the most frequent answer and its count; ties go to the earliest answer
+func majorityAnswer(answers []string) (string, int) {
+	counts := map[string]int{}
+	for _, a := range answers {
+		counts[normalizeAnswer(a)]++
+	}
+	best, votes := "", 0
+	for _, a := range answers {
+		if n := counts[normalizeAnswer(a)]; n > votes {
+			best, votes = a, n
+		}
+	}
+	return best, votes
+}
+
+func normalizeAnswer(answer string) string {
+	return strings.ToLower(strings.Join(strings.Fields(answer), " "))
+}
@@ -0,0 +1,144 @@
+package computeruse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// x11Keysyms maps key names emitted by the model to X11 keysym names and values
+var x11Keysyms = map[string]struct {
+	name string
+	code uint32
+}{
+	"enter": {"Return", 0xff0d}, "return": {"Return", 0xff0d}, "tab": {"Tab", 0xff09},
+	"space": {"space", 0x20}, "backspace": {"BackSpace", 0xff08}, "escape": {"Escape", 0xff1b},
+	"esc": {"Escape", 0xff1b}, "delete": {"Delete", 0xffff}, "home": {"Home", 0xff50}, "end": {"End", 0xff57},
+	"page_up": {"Page_Up", 0xff55}, "pageup": {"Page_Up", 0xff55}, "page_down": {"Page_Down", 0xff56},
+	"pagedown": {"Page_Down", 0xff56}, "left": {"Left", 0xff51}, "arrowleft": {"Left", 0xff51},
+	"up": {"Up", 0xff52}, "arrowup": {"Up", 0xff52}, "right": {"Right", 0xff53}, "arrowright": {"Right", 0xff53},
+	"down": {"Down", 0xff54}, "arrowdown": {"Down", 0xff54},
+	"shift": {"shift", 0xffe1}, "ctrl": {"ctrl", 0xffe3}, "control": {"ctrl", 0xffe3},
+	"alt": {"alt", 0xffe9}, "option": {"alt", 0xffe9},
+	"cmd": {"super", 0xffeb}, "command": {"super", 0xffeb}, "meta": {"super", 0xffeb}, "super": {"super", 0xffeb},
+}
+
+// x11Keysym returns the keysym name and value of a key name or single character
+func x11Keysym(key string) (string, uint32, error) {
+	if k, ok := x11Keysyms[strings.ToLower(key)]; ok {
+		return k.name, k.code, nil
+	}
+	if r := []rune(key); len(r) == 1 {
+		code := uint32(r[0])
+		if code > 0xff {
+			code |= 0x01000000 // Unicode keysym
+		}
+		return fmt.Sprintf("0x%x", code), code, nil
+	}
+	return "", 0, fmt.Errorf("key: %v is not supported", key)
+}
+
+// x11Driver drives an X11 display with xdotool and ImageMagick's import
+type x11Driver struct {
+	display string
+}
+
+// NewX11Desktop returns a Desktop for an X11 display such as ":0" or the display
+// of a desktop container. It requires xdotool and ImageMagick to be installed.
+func NewX11Desktop(ctx context.Context, display string) (*Desktop, error) {
+	return newDesktop(ctx, &x11Driver{display: display})
+}
+
+func (x *x11Driver) environment() string {
+	return "ubuntu"
+}
+
+func (x *x11Driver) command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), "DISPLAY="+x.display)
+	return cmd
+}
+
+func (x *x11Driver) xdotool(ctx context.Context, args ...string) error {
+	out, err := x.command(ctx, "xdotool", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("xdotool failed: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (x *x11Driver) screenshot(ctx context.Context) ([]byte, error) {
+	out, err := x.command(ctx, "import", "-window", "root", "png:-").Output()
+	if err != nil {
+		return nil, fmt.Errorf("import failed: %w", err)
+	}
+	return out, nil
+}
+
+func (x *x11Driver) move(ctx context.Context, px, py int) error {
+	return x.xdotool(ctx, "mousemove", strconv.Itoa(px), strconv.Itoa(py))
+}
+
+func (x *x11Driver) click(ctx context.Context, px, py int, button string, count int) error {
+	btn := "1"
+	if button == "right" {
+		btn = "3"
+	}
+	return x.xdotool(ctx, "mousemove", strconv.Itoa(px), strconv.Itoa(py), "click", "--repeat", strconv.Itoa(count), btn)
+}
+
+func (x *x11Driver) scroll(ctx context.Context, px, py, scrollX, scrollY int) error {
+	if err := x.move(ctx, px, py); err != nil {
+		return err
+	}
+	for _, s := range scrollSteps(scrollX, scrollY) {
+		if err := x.xdotool(ctx, "click", "--repeat", strconv.Itoa(s.count), strconv.Itoa(s.button)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *x11Driver) typeText(ctx context.Context, text string) error {
+	return x.xdotool(ctx, "type", "--delay", "0", "--", text)
+}
+
+func (x *x11Driver) pressKeys(ctx context.Context, keys []string) error {
+	names := make([]string, 0, len(keys))
+	for _, key := range keys {
+		name, _, err := x11Keysym(key)
+		if err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+	return x.xdotool(ctx, "key", strings.Join(names, "+"))
+}
+
+// scrollStep is a number of clicks on an X11 wheel button
+type scrollStep struct {
+	button int
+	count  int
+}
+
+// scrollSteps converts a pixel scroll distance into X11 wheel button clicks
+// (4 up, 5 down, 6 left, 7 right), one click per 100 pixels
+func scrollSteps(scrollX, scrollY int) []scrollStep {
+	var steps []scrollStep
+	add := func(delta, negative, positive int) {
+		if delta == 0 {
+			return
+		}
+		button := positive
+		if delta < 0 {
+			button, delta = negative, -delta
+		}
+		steps = append(steps, scrollStep{button: button, count: max(1, delta/100)})
+	}
+	add(scrollY, 4, 5)
+	add(scrollX, 6, 7)
+	return steps
+}